@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/spf13/cobra"
+)
+
+var cancelSchedule string
+
+var cancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel a scheduled move during its countdown",
+	Long: `Cancel the scheduled moves the running daemon announced and that are still
+counting down, or only the one of --schedule. The schedules keep running at
+their next times.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		countdowns, err := client.Cancel(cancelSchedule)
+		if errors.Is(err, control.ErrDaemonNotRunning) {
+			log.Fatal("the daemon is not running, start it with 'idasenctl daemon'")
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, countdown := range countdowns {
+			fmt.Printf("Cancelled schedule '%s' for desk %s at %s (%s)\n",
+				countdown.Schedule, countdown.Desk, countdown.At.Format("15:04"), countdown.Action)
+		}
+	},
+}
+
+func init() {
+	cancelCmd.Flags().StringVarP(&cancelSchedule, "schedule", "s", "", "Only cancel the move of this schedule")
+
+	rootCmd.AddCommand(cancelCmd)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configExportDesk     string
+	configExportOutput   string
+	configImportDesk     string
+	configImportStrategy string
+	configImportDryRun   bool
+	configImportCommands bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the idasenctl configuration",
+	Long:  `Export, import and merge desks, presets and schedules.`,
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export desks, presets and schedules",
+	Long:  `Export desks, presets and schedules as YAML, optionally only those of a single desk.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		exported, err := configManager.Export(configExportDesk)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		b, err := yaml.Marshal(exported)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if configExportOutput == "" || configExportOutput == "-" {
+			fmt.Print(string(b))
+			return
+		}
+
+		err = os.WriteFile(configExportOutput, b, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Config exported to %s\n", configExportOutput)
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Merge an exported file into the current config",
+	Long: `Merge desks, presets and schedules from an exported file into the current config.
+
+Conflicting entries are resolved with --strategy:
+  skip       keep the current entry
+  overwrite  replace the current entry with the imported one
+  rename     keep both, adding the imported entry under a new name
+
+Use --desk to apply the presets and schedules of a single exported desk to one
+of your own desks, and --dry-run to only print the changes.
+
+Schedules that run a command are only imported with --allow-commands, as
+they run the command on this computer.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		strategy, err := config.ParseConflictStrategy(configImportStrategy)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var b []byte
+		if args[0] == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		incoming, err := config.ParseConfig(b)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		changes, err := configManager.Import(incoming, config.ImportOptions{
+			Strategy:      strategy,
+			TargetDesk:    configImportDesk,
+			DryRun:        configImportDryRun,
+			AllowCommands: configImportCommands,
+			ValidateSchedule: func(schedule *config.Schedule, desk config.Desk) error {
+				err := scheduler.Validate(*schedule)
+				if err != nil {
+					return err
+				}
+				return validateScheduleOnDesk(schedule, desk)
+			},
+		})
+		if errors.Is(err, config.ErrImportCommands) {
+			log.Fatalf("%v\nCheck the commands and import again with --allow-commands to run them on this computer", err)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("Nothing to import, config is up to date")
+			return
+		}
+
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+
+		if configImportDryRun {
+			if commands := config.CommandChanges(changes); len(commands) > 0 && !configImportCommands {
+				fmt.Println("\nThese schedules run commands on this computer and are only imported with --allow-commands:")
+				for _, c := range commands {
+					fmt.Printf("  %s: %s\n", c.ScheduleName(), c.Command)
+				}
+			}
+			fmt.Println("Dry run, no changes were written")
+			return
+		}
+		reloadDaemon()
+		fmt.Printf("Imported %d changes\n", len(changes))
+	},
+}
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List previous versions of the config",
+	Long:  `List the saved previous versions of the config, newest first, with the command that changed them.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := configManager.GetHistory()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No config history")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCHANGED AT\tCOMMAND")
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := entries[i]
+			fmt.Fprintf(w, "%d\t%s\t%s\n", entry.ID, entry.Time.Format("2006-01-02 15:04:05"), entry.Command)
+		}
+		w.Flush()
+	},
+}
+
+var configUndoCmd = &cobra.Command{
+	Use:   "undo [id]",
+	Short: "Restore a previous version of the config",
+	Long:  `Undo the latest config change, or every change back to and including the history entry with the given id.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := 0
+		if len(args) == 1 {
+			var err error
+			id, err = strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatalf("invalid history id: %s", args[0])
+			}
+		}
+
+		entry, err := configManager.Undo(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Undid '%s' from %s\n", entry.Command, entry.Time.Format("2006-01-02 15:04:05"))
+	},
+}
+
+func init() {
+	configExportCmd.Flags().StringVarP(&configExportDesk, "desk", "d", "", "Only export this desk and its schedules")
+	configExportCmd.Flags().StringVarP(&configExportOutput, "output", "o", "", "File to write to (defaults to stdout)")
+
+	configImportCmd.Flags().StringVarP(&configImportDesk, "desk", "d", "", "Apply the single desk in the file to this local desk")
+	configImportCmd.Flags().StringVarP(&configImportStrategy, "strategy", "s", string(config.ConflictSkip), "Conflict strategy: skip, overwrite or rename")
+	configImportCmd.Flags().BoolVar(&configImportDryRun, "dry-run", false, "Print the changes without writing them")
+	configImportCmd.Flags().BoolVar(&configImportCommands, "allow-commands", false, "Import schedules that run commands")
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configUndoCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
@@ -1,25 +1,48 @@
 package cmd
 
 import (
+	"errors"
 	"log"
 
+	"github.com/samueltorres/idasenctl/internal/control"
 	"github.com/samueltorres/idasenctl/internal/daemon"
 	"github.com/spf13/cobra"
 )
 
+var daemonMonitor bool
+
 var daemonCmd = &cobra.Command{
 	Use:   "daemon",
 	Short: "Run idasenctl as a daemon with scheduled desk movements",
-	Long:  `Run idasenctl as a background daemon that will automatically move your desk based on configured schedules.`,
+	Long: `Run idasenctl as a background daemon that will automatically move your desk based on configured schedules.
+
+With --monitor the daemon stays connected to every desk, reconnecting when the
+connection drops, and records every height change, including moves made with
+the desk's buttons, and the height of every desk every 5 minutes. Other apps
+cannot connect to a desk while it is monitored.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		d := daemon.NewDaemon(configManager)
-		err := d.Start()
+		d, err := daemon.NewDaemon(configManager, daemon.Options{Monitor: daemonMonitor})
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = d.Start()
 		if err != nil {
 			log.Fatal(err)
 		}
 	},
 }
 
+// reloadDaemon makes a running daemon pick up config changes right away.
+func reloadDaemon() {
+	client := control.NewClient(control.SocketFile(configManager.Path()))
+	err := client.Reload()
+	if err != nil && !errors.Is(err, control.ErrDaemonNotRunning) {
+		log.Printf("could not reload the running daemon, restart it to apply the change: %v", err)
+	}
+}
+
 func init() {
+	daemonCmd.Flags().BoolVar(&daemonMonitor, "monitor", false, "Keep a connection to every desk to observe all height changes")
+
 	rootCmd.AddCommand(daemonCmd)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/samueltorres/idasenctl/internal/config"
 	"github.com/samueltorres/idasenctl/internal/idasen"
@@ -56,6 +57,7 @@ var deskAddCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal(err)
 		}
+		reloadDaemon()
 
 		fmt.Println("Selected desk:", selectedDesk.Name)
 	},
@@ -71,6 +73,7 @@ var deskDefaultCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal(err)
 		}
+		reloadDaemon()
 	},
 }
 
@@ -87,10 +90,54 @@ var deskListCmd = &cobra.Command{
 	},
 }
 
+var deskStandingHeightCmd = &cobra.Command{
+	Use:   "standing-height [height]",
+	Short: "Set the height from which the desk counts as standing",
+	Long: fmt.Sprintf(`Set the height, in meters, from which the desk counts as standing in
+statistics. Without a height the current setting is shown. Desks without a
+standing height use %.2f m.`, config.DefaultStandingHeight),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		deskName := deskFlag
+		if deskName == "" {
+			deskName = configManager.GetDefaultDesk()
+		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(args) == 0 {
+			fmt.Printf("Desk %s counts as standing from %.2f m\n", desk.Name, desk.StandingThreshold())
+			return
+		}
+
+		height, err := strconv.ParseFloat(args[0], 32)
+		if err != nil {
+			log.Fatalf("invalid height: %s", args[0])
+		}
+		err = idasen.ValidateHeight(float32(height))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.SetDeskStandingHeight(desk.Name, float32(height))
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Desk %s now counts as standing from %.2f m\n", desk.Name, height)
+	},
+}
+
 func init() {
+	deskStandingHeightCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
+
 	deskCmd.AddCommand(deskAddCmd)
 	deskCmd.AddCommand(deskDefaultCmd)
 	deskCmd.AddCommand(deskListCmd)
+	deskCmd.AddCommand(deskStandingHeightCmd)
 
 	rootCmd.AddCommand(deskCmd)
 }
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/ical"
+	"github.com/spf13/cobra"
+)
+
+var (
+	excludeSchedule string
+	excludeReason   string
+	excludeUntil    string
+)
+
+var excludeCmd = &cobra.Command{
+	Use:   "exclude",
+	Short: "Manage the days on which schedules do not run",
+	Long: `Manage exclusions, days or ranges of days such as public holidays and
+vacations on which schedules do not run. Exclusions apply to every schedule,
+or only to the one given with --schedule.`,
+}
+
+var excludeAddCmd = &cobra.Command{
+	Use:   "add [start] [end]",
+	Short: "Exclude a day or a range of days",
+	Long: `Exclude a day, or the days from start to end inclusive, given as YYYY-MM-DD.
+
+  idasenctl exclude add 2026-12-25 --reason Christmas
+  idasenctl exclude add 2026-08-03 2026-08-21 --reason Vacation`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		exclusion := config.Exclusion{
+			Start:  args[0],
+			Reason: excludeReason,
+		}
+		if len(args) == 2 {
+			exclusion.End = args[1]
+		}
+
+		added, err := configManager.AddExclusions(excludeSchedule, exclusion)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if added == 0 {
+			fmt.Printf("%s is already excluded\n", exclusion)
+			return
+		}
+		reloadDaemon()
+
+		fmt.Printf("Excluded %s %s\n", exclusion, exclusionTarget(excludeSchedule))
+	},
+}
+
+var excludeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the exclusions",
+	Long:  `List the exclusions of every schedule, or only those of --schedule.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SCHEDULE\tSTART\tEND\tREASON")
+		count := 0
+		printExclusions := func(scheduleName string, exclusions []config.Exclusion) {
+			for _, exclusion := range exclusions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", scheduleName, exclusion.Start, exclusion.LastDay(), exclusion.Reason)
+				count++
+			}
+		}
+
+		global, _ := configManager.GetExclusions("")
+		if excludeSchedule == "" {
+			printExclusions("(all)", global)
+			for _, schedule := range configManager.GetSchedules() {
+				printExclusions(schedule.Name, schedule.Exclusions)
+			}
+		} else {
+			exclusions, err := configManager.GetExclusions(excludeSchedule)
+			if err != nil {
+				log.Fatal(err)
+			}
+			printExclusions("(all)", global)
+			printExclusions(excludeSchedule, exclusions)
+		}
+
+		if count == 0 {
+			fmt.Println("No exclusions configured")
+			return
+		}
+		w.Flush()
+	},
+}
+
+var excludeRemoveCmd = &cobra.Command{
+	Use:   "remove [start]",
+	Short: "Remove an exclusion",
+	Long:  `Remove the exclusion starting on the given day, YYYY-MM-DD.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := configManager.RemoveExclusion(excludeSchedule, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Exclusion starting on %s removed %s\n", args[0], exclusionTarget(excludeSchedule))
+	},
+}
+
+var excludeImportCmd = &cobra.Command{
+	Use:   "import [file.ics]",
+	Short: "Import exclusions from an iCalendar file",
+	Long: `Import the all-day events of a local iCalendar (.ics) file, such as a
+calendar of public holidays, as exclusions. Events with a time of day are
+skipped. Recurring events are imported from today until --until, one year
+from today by default; those that repeat in ways that are not supported,
+such as on the fourth Thursday of November, are skipped and listed.
+
+  idasenctl exclude import holidays.ics
+  idasenctl exclude import holidays.ics --until 2028-12-31`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		f, err := os.Open(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		events, err := ical.Parse(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		now := time.Now()
+		from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		until := from.AddDate(1, 0, 1)
+		if excludeUntil != "" {
+			day, err := time.ParseInLocation(config.DateFormat, excludeUntil, time.Local)
+			if err != nil {
+				log.Fatalf("invalid --until %q, must be YYYY-MM-DD", excludeUntil)
+			}
+			until = day.AddDate(0, 0, 1)
+		}
+
+		var exclusions []config.Exclusion
+		var unsupported []string
+		timed, recurring := 0, 0
+		for _, event := range events {
+			if !event.AllDay {
+				timed++
+				continue
+			}
+			if !event.Recurring() {
+				exclusions = append(exclusions, eventExclusion(event))
+				continue
+			}
+
+			occurrences, err := event.Occurrences(from, until)
+			if errors.Is(err, ical.ErrUnsupportedRule) {
+				unsupported = append(unsupported, fmt.Sprintf("%s (%v)", event.Summary, err))
+				continue
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			recurring++
+			for _, occurrence := range occurrences {
+				exclusions = append(exclusions, eventExclusion(occurrence))
+			}
+		}
+
+		added, err := configManager.AddExclusions(excludeSchedule, exclusions...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if added > 0 {
+			reloadDaemon()
+		}
+
+		fmt.Printf("Imported %d exclusions %s", added, exclusionTarget(excludeSchedule))
+		if existing := len(exclusions) - added; existing > 0 {
+			fmt.Printf(", %d already existed", existing)
+		}
+		fmt.Println()
+		if timed > 0 {
+			fmt.Printf("Skipped %d events with a time of day\n", timed)
+		}
+		if recurring > 0 {
+			fmt.Printf("Imported %d recurring events until %s\n", recurring, until.AddDate(0, 0, -1).Format(config.DateFormat))
+		}
+		if len(unsupported) > 0 {
+			fmt.Fprintf(os.Stderr, "Skipped %d recurring events that repeat in an unsupported way:\n  %s\n", len(unsupported), strings.Join(unsupported, "\n  "))
+		}
+	},
+}
+
+// eventExclusion returns the exclusion of the days of an all-day event.
+func eventExclusion(event ical.Event) config.Exclusion {
+	// The end of all-day events is the day after the last one.
+	end := event.End.AddDate(0, 0, -1)
+	if end.Before(event.Start) {
+		end = event.Start
+	}
+	return config.Exclusion{
+		Start:  event.Start.Format(config.DateFormat),
+		End:    end.Format(config.DateFormat),
+		Reason: event.Summary,
+	}
+}
+
+func exclusionTarget(scheduleName string) string {
+	if scheduleName == "" {
+		return "for all schedules"
+	}
+	return fmt.Sprintf("for schedule '%s'", scheduleName)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{excludeAddCmd, excludeListCmd, excludeRemoveCmd, excludeImportCmd} {
+		c.Flags().StringVarP(&excludeSchedule, "schedule", "s", "", "Schedule the exclusions belong to, all schedules when empty")
+		excludeCmd.AddCommand(c)
+	}
+	excludeImportCmd.Flags().StringVar(&excludeUntil, "until", "", "Last day to import recurring events for, YYYY-MM-DD, one year from today by default")
+	excludeAddCmd.Flags().StringVarP(&excludeReason, "reason", "r", "", "Why the days are excluded, such as the name of the holiday")
+
+	rootCmd.AddCommand(excludeCmd)
+}
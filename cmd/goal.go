@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/goal"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	goalDesk        string
+	goalStanding    time.Duration
+	goalHours       string
+	goalDays        []string
+	goalBehindBy    time.Duration
+	goalRemindEvery time.Duration
+	goalAutoMove    bool
+	goalPreset      string
+)
+
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "Manage the daily standing goal",
+	Long: `Set a daily standing goal, such as 2h a day during work hours. While the
+daemon runs it reminds you, or moves the desk, when you fall behind pace.`,
+}
+
+var goalSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the daily standing goal",
+	Long: `Set the daily standing goal. The goal is spread evenly over the work hours;
+when the time spent standing lags that pace by --behind, the daemon sends a
+reminder, or with --auto-move moves the desk to --preset.
+
+  idasenctl goal set --standing 2h --hours 09:00-17:00 --behind 20m`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := config.ParseTimeRange(goalHours)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		days, err := config.ParseDays(goalDays)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		standingGoal := config.Goal{
+			DeskName:    goalDesk,
+			Standing:    goalStanding,
+			Start:       start,
+			End:         end,
+			Days:        days,
+			BehindBy:    goalBehindBy,
+			RemindEvery: goalRemindEvery,
+			AutoMove:    goalAutoMove,
+			Preset:      goalPreset,
+		}
+
+		err = goal.Validate(standingGoal)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.SetGoal(&standingGoal)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Standing goal set to %s a day from %s to %s on %s\n",
+			stats.FormatDuration(standingGoal.Standing), standingGoal.Start, standingGoal.End, config.FormatDays(standingGoal.Days))
+	},
+}
+
+var goalStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show today's progress towards the standing goal",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		standingGoal := configManager.GetGoal()
+		if standingGoal == nil {
+			fmt.Println("No standing goal set, use 'idasenctl goal set' to set one")
+			return
+		}
+
+		deskName := standingGoal.DeskName
+		if deskName == "" {
+			deskName = configManager.GetDefaultDesk()
+		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		progress, err := goal.Load(
+			runHistory,
+			history.NewSampleStore(history.SamplesFile(configManager.Path())),
+			desk, *standingGoal, time.Now(),
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		const barWidth = 30
+		filled := int(progress.Ratio() * barWidth)
+		fmt.Printf("[%s%s] %s of %s standing (%.0f%%)\n",
+			strings.Repeat("#", filled), strings.Repeat(".", barWidth-filled),
+			stats.FormatDuration(progress.Standing), stats.FormatDuration(standingGoal.Standing), progress.Ratio()*100)
+
+		switch {
+		case progress.Done():
+			fmt.Println("Goal reached for today")
+		case !progress.WorkDay:
+			fmt.Println("The goal does not apply today")
+		case progress.Behind() > 0:
+			fmt.Printf("%s behind pace, %s expected by now\n",
+				stats.FormatDuration(progress.Behind()), stats.FormatDuration(progress.Expected))
+		default:
+			fmt.Println("On pace")
+		}
+
+		action := "reminders"
+		if standingGoal.AutoMove {
+			action = "auto-move to " + standingGoal.Preset
+		}
+		fmt.Printf("Work hours %s-%s on %s, %s when %s behind\n",
+			standingGoal.Start, standingGoal.End, config.FormatDays(standingGoal.Days), action, stats.FormatDuration(standingGoal.BehindBy))
+	},
+}
+
+var goalRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the standing goal",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := configManager.SetGoal(nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Println("Standing goal removed")
+	},
+}
+
+func init() {
+	goalSetCmd.Flags().StringVarP(&goalDesk, "desk", "d", "", "Desk name (defaults to default desk)")
+	goalSetCmd.Flags().DurationVar(&goalStanding, "standing", 0, "Time to stand each day (e.g., 2h)")
+	goalSetCmd.Flags().StringVar(&goalHours, "hours", "09:00-17:00", "Work hours over which the goal is spread (e.g., 09:00-17:00 or 9am-5pm)")
+	goalSetCmd.Flags().StringSliceVar(&goalDays, "days", []string{"weekdays"}, "Days the goal applies to (e.g., weekdays or mon-thu)")
+	goalSetCmd.Flags().DurationVar(&goalBehindBy, "behind", 30*time.Minute, "How far behind pace before a reminder")
+	goalSetCmd.Flags().DurationVar(&goalRemindEvery, "remind-every", 0, "Minimum time between reminders (default 30m)")
+	goalSetCmd.Flags().BoolVar(&goalAutoMove, "auto-move", false, "Move the desk to --preset instead of only reminding")
+	goalSetCmd.Flags().StringVarP(&goalPreset, "preset", "p", "", "Preset to move to with --auto-move")
+	goalSetCmd.MarkFlagRequired("standing")
+
+	goalCmd.AddCommand(goalSetCmd)
+	goalCmd.AddCommand(goalStatusCmd)
+	goalCmd.AddCommand(goalRemoveCmd)
+
+	rootCmd.AddCommand(goalCmd)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyDesk     string
+	historyTrigger  string
+	historyOutcome  string
+	historySchedule string
+	historySince    string
+	historyUntil    string
+	historyLimit    int
+	historyOutput   string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show past desk moves",
+	Long: `Show the desk moves made by the daemon and from the command line, including
+failed moves and scheduled runs that were skipped.
+
+--since and --until take a date (2006-01-02), a date and time
+(2006-01-02 15:04) or a duration back from now (e.g., 24h or 7d).`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter := history.Filter{
+			Desk:     historyDesk,
+			Schedule: historySchedule,
+			Limit:    historyLimit,
+		}
+
+		var err error
+		if historyTrigger != "" {
+			filter.Trigger, err = history.ParseTrigger(historyTrigger)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if historyOutcome != "" {
+			filter.Outcome, err = history.ParseOutcome(historyOutcome)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if historySince != "" {
+			filter.Since, err = parseHistoryTime(historySince)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if historyUntil != "" {
+			filter.Until, err = parseHistoryTime(historyUntil)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		records, err := runHistory.Query(filter)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		switch historyOutput {
+		case "json":
+			if records == nil {
+				records = []history.Record{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(records)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "text":
+			if len(records) == 0 {
+				fmt.Println("No moves recorded")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tDESK\tTRIGGER\tPRESET\tFROM\tTO\tDURATION\tOUTCOME")
+			for _, r := range records {
+				trigger := string(r.Trigger)
+				if r.Schedule != "" {
+					trigger += " " + r.Schedule
+				}
+				outcome := string(r.Outcome)
+				if r.Attempts > 1 {
+					outcome += fmt.Sprintf(" after %d attempts", r.Attempts)
+				}
+				if r.Reason != "" {
+					outcome += ": " + r.Reason
+				}
+				if !r.ScheduledAt.IsZero() {
+					outcome += fmt.Sprintf(" (due %s)", r.ScheduledAt.Local().Format("2006-01-02 15:04"))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					r.Time.Local().Format("2006-01-02 15:04:05"), r.Desk, trigger, r.Preset,
+					formatHistoryHeight(r.FromHeight), formatHistoryHeight(r.ToHeight),
+					r.Duration().Round(100*time.Millisecond), outcome)
+			}
+			w.Flush()
+		default:
+			log.Fatalf("invalid output format %q, must be text or json", historyOutput)
+		}
+	},
+}
+
+// parseHistoryTime parses a date, a date and time or a duration back from now.
+func parseHistoryTime(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") {
+		return time.Now().AddDate(0, 0, -days), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a date, a date and time or a duration", s)
+}
+
+func formatHistoryHeight(height float32) string {
+	if height == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f m", height)
+}
+
+// recordMove appends a move started from the command line to the run history.
+func recordMove(record history.Record) {
+	err := runHistory.Append(record)
+	if err != nil {
+		log.Printf("Error recording move in history: %v", err)
+	}
+}
+
+func recordFailedMove(record history.Record, err error) {
+	record.Outcome = history.OutcomeFailed
+	record.Reason = err.Error()
+	record.DurationMs = time.Since(record.Time).Milliseconds()
+	recordMove(record)
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyDesk, "desk", "d", "", "Only show moves of this desk")
+	historyCmd.Flags().StringVar(&historyTrigger, "trigger", "", "Only show moves with this trigger: schedule, cli, api or goal")
+	historyCmd.Flags().StringVar(&historyOutcome, "outcome", "", "Only show moves with this outcome: success, failed, skipped or cancelled")
+	historyCmd.Flags().StringVar(&historySchedule, "schedule", "", "Only show runs of this schedule")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only show moves after this time")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only show moves before this time")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Show at most this many of the latest moves (0 for all)")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "text", "Output format: text or json")
+
+	rootCmd.AddCommand(historyCmd)
+}
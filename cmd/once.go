@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	onceDesk string
+	onceName string
+)
+
+var atCmd = &cobra.Command{
+	Use:   "at [time] [preset]",
+	Short: "Move the desk to a preset once, at a time",
+	Long: `Schedule a single move of the desk to a preset at a time of day, the next
+time it comes, or at a day and time (YYYY-MM-DD HH:MM). The running daemon
+moves the desk, and the schedule is listed and can be removed like any other
+schedule until then. Times are in the default time zone of schedules, set with
+'idasenctl schedule timezone'.
+
+  idasenctl at 15:30 stand
+  idasenctl at "2026-10-20 9am" sit`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Times are in the default time zone of schedules.
+		loc, err := config.LoadLocation(configManager.GetTimezone())
+		if err != nil {
+			log.Fatal(err)
+		}
+		at, err := parseAt(args[0], time.Now().In(loc))
+		if err != nil {
+			log.Fatal(err)
+		}
+		addOnce(at, args[1])
+	},
+}
+
+var inCmd = &cobra.Command{
+	Use:   "in [duration] [preset]",
+	Short: "Move the desk to a preset once, after a while",
+	Long: `Schedule a single move of the desk to a preset after a duration. The running
+daemon moves the desk, and the schedule is listed and can be removed like any
+other schedule until then.
+
+  idasenctl in 40m sit`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if d <= 0 {
+			log.Fatal("the duration must be positive")
+		}
+		addOnce(time.Now().Add(d).Truncate(time.Second), args[1])
+	},
+}
+
+// addOnce stores a one-off schedule moving the desk to the preset at at.
+func addOnce(at time.Time, presetName string) {
+	deskName := onceDesk
+	if deskName == "" {
+		deskName = configManager.GetDefaultDesk()
+	}
+
+	schedule := config.Schedule{
+		Name:         "once",
+		Kind:         config.KindOnce,
+		At:           at,
+		DeskName:     deskName,
+		PresetName:   presetName,
+		Enabled:      true,
+		MissedPolicy: config.MissedRunLatest,
+	}
+	err := validateSchedule(&schedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The name uses the preset name resolved by validateSchedule.
+	schedule.Name = onceName
+	if schedule.Name == "" {
+		schedule.Name = onceScheduleName(schedule.PresetName, at)
+	}
+
+	err = configManager.AddSchedule(schedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reloadDaemon()
+
+	in := stats.FormatDuration(time.Until(at))
+	if time.Until(at) < time.Minute {
+		in = time.Until(at).Round(time.Second).String()
+	}
+	fmt.Printf("Desk %s will move to preset '%s' at %s (in %s)\n", schedule.DeskName, schedule.PresetName,
+		at.Format("Mon 2006-01-02 15:04"), in)
+	fmt.Printf("Remove it with 'idasenctl schedule remove %s'\n", schedule.Name)
+	warnConflicts(schedule)
+}
+
+// onceScheduleName returns an unused name like "once-stand-1530".
+func onceScheduleName(presetName string, at time.Time) string {
+	base := fmt.Sprintf("once-%s-%s", presetName, at.Format("1504"))
+	name := base
+	for i := 2; ; i++ {
+		if _, err := configManager.GetSchedule(name); err != nil {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// parseAt parses a time of day, which is the next time it comes, or a day
+// and time, in the location of now.
+func parseAt(s string, now time.Time) (time.Time, error) {
+	loc := now.Location()
+	if clock, err := config.ParseTimeOfDay(s); err == nil {
+		t, _ := time.ParseInLocation("15:04", clock, loc)
+		year, month, day := now.Date()
+		at := time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, loc)
+		if !at.After(now) {
+			at = time.Date(year, month, day+1, t.Hour(), t.Minute(), 0, 0, loc)
+		}
+		return at, nil
+	}
+
+	for _, sep := range []string{" ", "T"} {
+		i := strings.LastIndex(s, sep)
+		if i < 0 {
+			continue
+		}
+		d, err := time.ParseInLocation(config.DateFormat, s[:i], loc)
+		if err != nil {
+			continue
+		}
+		clock, err := config.ParseTimeOfDay(s[i+1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		t, _ := time.ParseInLocation("15:04", clock, loc)
+		at := time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		if !at.After(now) {
+			return time.Time{}, fmt.Errorf("%s is not in the future", s)
+		}
+		return at, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a time of day such as 15:30 or 3:30pm, or YYYY-MM-DD HH:MM", s)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{atCmd, inCmd} {
+		c.Flags().StringVarP(&onceDesk, "desk", "d", "", "Desk name (defaults to default desk)")
+		c.Flags().StringVarP(&onceName, "name", "n", "", "Name of the schedule (defaults to once-<preset>-<HHMM>)")
+		rootCmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var pauseUntil string
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause all schedules",
+	Long: `Pause all schedules until resumed, or until --until, which is a day
+(YYYY-MM-DD, schedules run again from the start of that day), a day and time
+(YYYY-MM-DD HH:MM) or a duration such as 2h. The pause is kept when the
+daemon restarts.
+
+  idasenctl pause --until 2026-11-02`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var until time.Time
+		if pauseUntil != "" {
+			var err error
+			until, err = parseUntil(pauseUntil, time.Now())
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		err := client.Pause(until)
+		if errors.Is(err, control.ErrDaemonNotRunning) {
+			err = updateState(func(s *state.State) {
+				s.Paused = true
+				s.PausedUntil = until
+			})
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if until.IsZero() {
+			fmt.Println("Schedules paused until resumed")
+			return
+		}
+		fmt.Printf("Schedules paused until %s\n", until.Format("Mon 2006-01-02 15:04"))
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume paused schedules",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		err := client.Resume()
+		if errors.Is(err, control.ErrDaemonNotRunning) {
+			err = updateState(func(s *state.State) {
+				s.Paused = false
+				s.PausedUntil = time.Time{}
+			})
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("Schedules resumed")
+	},
+}
+
+// updateState changes the daemon's state file while the daemon is not
+// running.
+func updateState(fn func(s *state.State)) error {
+	store, err := state.NewStore(state.StateFile(configManager.Path()))
+	if err != nil {
+		return err
+	}
+	return store.Update(fn)
+}
+
+// parseUntil parses the end of a pause: a day, a day and time or a duration
+// from now.
+func parseUntil(s string, now time.Time) (time.Time, error) {
+	var until time.Time
+	if t, err := time.ParseInLocation(config.DateFormat, s, time.Local); err == nil {
+		until = t
+	} else if t, err := time.ParseInLocation(config.DateFormat+" 15:04", s, time.Local); err == nil {
+		until = t
+	} else if t, err := time.ParseInLocation(config.DateFormat+"T15:04", s, time.Local); err == nil {
+		until = t
+	} else if d, err := time.ParseDuration(s); err == nil {
+		until = now.Add(d)
+	} else {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD, YYYY-MM-DD HH:MM or a duration such as 2h", s)
+	}
+
+	if !until.After(now) {
+		return time.Time{}, fmt.Errorf("%s is not in the future", s)
+	}
+	return until, nil
+}
+
+func init() {
+	pauseCmd.Flags().StringVarP(&pauseUntil, "until", "u", "", "When the schedules run again, resumed by hand when empty")
+
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Switch between sets of schedules",
+	Long: `Group schedules into plans, such as wfh and office, with the --plan flag of
+'schedule add' and 'schedule edit'. Schedules of a plan only run while the plan
+is in use, schedules without a plan always run.
+
+A plan is in use every day after 'plan use', or on the weekdays it is assigned
+to with 'plan assign' otherwise.`,
+}
+
+var planListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plans",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		plans := configManager.GetPlans()
+		if len(plans) == 0 {
+			fmt.Println("No plans configured, add schedules to one with 'idasenctl schedule add --plan'")
+			return
+		}
+
+		active := configManager.GetActivePlan()
+		days := make(map[string][]int)
+		for day, plan := range configManager.GetPlanDays() {
+			days[plan] = append(days[plan], day)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLAN\tSCHEDULES\tDAYS\tIN USE")
+		for _, plan := range plans {
+			var schedules []string
+			for _, schedule := range configManager.GetSchedules() {
+				if schedule.Plan == plan {
+					schedules = append(schedules, schedule.Name)
+				}
+			}
+
+			assigned := "-"
+			if len(days[plan]) > 0 {
+				assigned = config.FormatDays(days[plan])
+			}
+
+			inUse := ""
+			switch {
+			case active == plan:
+				inUse = "every day"
+			case active == "" && configManager.PlanOn(time.Now()) == plan:
+				inUse = "today"
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", plan, strings.Join(schedules, ", "), assigned, inUse)
+		}
+		w.Flush()
+
+		if active != "" {
+			fmt.Printf("\nPlan '%s' is in use every day, 'idasenctl plan use auto' follows the weekdays again\n", active)
+		}
+	},
+}
+
+var planUseCmd = &cobra.Command{
+	Use:   "use [plan]",
+	Short: "Use a plan every day",
+	Long: `Use a plan every day, until another one is used. "auto" goes back to the
+plans assigned to the weekdays.
+
+  idasenctl plan use office
+  idasenctl plan use auto`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		plan := args[0]
+		if plan == config.AutoPlan {
+			plan = ""
+		}
+
+		err := configManager.UsePlan(plan)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		if plan == "" {
+			fmt.Println("Following the plans of the weekdays")
+			return
+		}
+		fmt.Printf("Using plan '%s'\n", plan)
+	},
+}
+
+var planAssignCmd = &cobra.Command{
+	Use:   "assign [plan] [days]",
+	Short: "Use a plan on weekdays",
+	Long: `Use a plan on the given weekdays, while no plan is used every day.
+
+  idasenctl plan assign wfh mon,fri
+  idasenctl plan assign office tue-thu`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		days, err := config.ParseDays(splitDays(args[1:]))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.AssignPlan(args[0], days)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Plan '%s' is used on %s\n", args[0], config.FormatDays(days))
+		if active := configManager.GetActivePlan(); active != "" && active != args[0] {
+			fmt.Printf("Plan '%s' is in use every day for now, 'idasenctl plan use auto' follows the weekdays again\n", active)
+		}
+	},
+}
+
+var planUnassignCmd = &cobra.Command{
+	Use:   "unassign [days]",
+	Short: "Remove the plans of weekdays",
+	Long: `Remove the plans of the given weekdays, so only schedules without a plan run
+on them.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		days, err := config.ParseDays(splitDays(args))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.AssignPlan("", days)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("No plan is used on %s\n", config.FormatDays(days))
+	},
+}
+
+// splitDays splits arguments like "mon,fri" into single days.
+func splitDays(args []string) []string {
+	return strings.Split(strings.Join(args, ","), ",")
+}
+
+func init() {
+	planCmd.AddCommand(planListCmd)
+	planCmd.AddCommand(planUseCmd)
+	planCmd.AddCommand(planAssignCmd)
+	planCmd.AddCommand(planUnassignCmd)
+
+	rootCmd.AddCommand(planCmd)
+}
@@ -1,17 +1,25 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
+	"os"
 
+	"github.com/mattn/go-isatty"
+	"github.com/samueltorres/idasenctl/internal/config"
 	"github.com/samueltorres/idasenctl/internal/idasen"
+	"github.com/samueltorres/idasenctl/internal/ui/heightinput"
 	"github.com/samueltorres/idasenctl/internal/ui/presetlist"
 	"github.com/spf13/cobra"
 )
 
 var (
-	deskFlag          string
-	deskPresetHeight  float32
-	deskPresetCurrent bool
+	deskFlag              string
+	deskPresetHeight      float32
+	deskPresetCurrent     bool
+	deskPresetDescription string
+	deskPresetOrder       int
+	deskPresetSort        string
 )
 
 // presetCmd represents the preset command
@@ -23,6 +31,10 @@ var presetCmd = &cobra.Command{
 var presetAddCmd = &cobra.Command{
 	Use:   "add [name]",
 	Short: "adds desk presets",
+	Long: `Add a preset with either an explicit --height or the --current height of the desk.
+
+When neither is given and idasenctl runs in a terminal, it prompts for the height.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		presetName := args[0]
 		deskName := deskFlag
@@ -31,11 +43,12 @@ var presetAddCmd = &cobra.Command{
 		}
 		desk, err := configManager.GetDesk(deskName)
 		if err != nil {
-			panic(err)
+			log.Fatal(err)
 		}
 
 		height := deskPresetHeight
-		if deskPresetCurrent {
+		switch {
+		case deskPresetCurrent:
 			controller, err := idasen.NewController(desk.Address)
 			if err != nil {
 				log.Fatal(err)
@@ -45,29 +58,144 @@ var presetAddCmd = &cobra.Command{
 			if err != nil {
 				log.Fatal(err)
 			}
+		case !cmd.Flags().Changed("height"):
+			if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+				log.Fatal("one of --height or --current is required")
+			}
+
+			prompt := heightinput.NewProgram(
+				fmt.Sprintf("Height for preset '%s' on %s", presetName, deskName),
+				fmt.Sprintf("%.2f - %.2f", idasen.IDASEN_MIN_HEIGHT, idasen.IDASEN_MAX_HEIGHT),
+				idasen.ValidateHeight,
+			)
+			err = prompt.Run()
+			if err != nil {
+				log.Fatal(err)
+			}
+			if prompt.GetHeight() == nil {
+				return
+			}
+			height = *prompt.GetHeight()
+		}
+
+		err = idasen.ValidateHeight(height)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.SaveDeskPreset(deskName, config.Preset{
+			Name:        presetName,
+			Height:      height,
+			Description: deskPresetDescription,
+			Order:       deskPresetOrder,
+		})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reloadDaemon()
+	},
+}
+
+var presetEditCmd = &cobra.Command{
+	Use:   "edit [name]",
+	Short: "edit a desk preset",
+	Long:  `Change the height, description or order of an existing preset.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		deskName := deskFlag
+		if deskName == "" {
+			deskName = configManager.GetDefaultDesk()
+		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		preset, err := config.ResolvePreset(desk, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if cmd.Flags().Changed("height") {
+			err = idasen.ValidateHeight(deskPresetHeight)
+			if err != nil {
+				log.Fatal(err)
+			}
+			preset.Height = deskPresetHeight
+		}
+		if cmd.Flags().Changed("description") {
+			preset.Description = deskPresetDescription
+		}
+		if cmd.Flags().Changed("order") {
+			preset.Order = deskPresetOrder
+		}
+
+		err = configManager.SaveDeskPreset(deskName, preset)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Preset '%s' updated\n", preset.Name)
+	},
+}
+
+var presetRenameCmd = &cobra.Command{
+	Use:   "rename [name] [new-name]",
+	Short: "rename a desk preset",
+	Long:  `Rename a preset, updating the schedules that use it.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		deskName := deskFlag
+		if deskName == "" {
+			deskName = configManager.GetDefaultDesk()
+		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		preset, err := config.LookupPreset(desk, args[0])
+		if err != nil {
+			log.Fatal(err)
 		}
 
-		err = configManager.SetDeskPreset(deskName, presetName, height)
+		err = configManager.RenameDeskPreset(deskName, preset.Name, args[1])
 		if err != nil {
 			log.Fatalln(err)
 		}
+		reloadDaemon()
+
+		fmt.Printf("Preset '%s' renamed to '%s'\n", preset.Name, args[1])
 	},
 }
 
 var presetDeleteCmd = &cobra.Command{
 	Use:   "delete [name]",
 	Short: "delete desk presets",
+	Long:  `Delete a preset. Presets still used by schedules or the standing goal are kept.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		presetName := args[0]
 		deskName := deskFlag
 		if deskName == "" {
 			deskName = configManager.GetDefaultDesk()
 		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-		err := configManager.DeleteDeskPreset(deskName, presetName)
+		preset, err := config.LookupPreset(desk, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.DeleteDeskPreset(deskName, preset.Name)
 		if err != nil {
 			log.Fatalln(err)
 		}
+		reloadDaemon()
+
+		fmt.Printf("Preset '%s' deleted\n", preset.Name)
 	},
 }
 
@@ -80,8 +208,13 @@ var presetListCmd = &cobra.Command{
 			deskName = configManager.GetDefaultDesk()
 		}
 
-		program := presetlist.NewProgram(configManager, deskName)
-		err := program.Run()
+		sortBy, err := config.ParsePresetSort(deskPresetSort)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		program := presetlist.NewProgram(configManager, deskName, sortBy)
+		err = program.Run()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -90,13 +223,25 @@ var presetListCmd = &cobra.Command{
 
 func init() {
 	presetAddCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
-	presetAddCmd.Flags().Float32VarP(&deskPresetHeight, "height", "", 0, "The height of the desk on the preset")
-	presetAddCmd.Flags().BoolVarP(&deskPresetCurrent, "current", "c", false, "The height of the desk on the preset")
+	presetAddCmd.Flags().Float32VarP(&deskPresetHeight, "height", "", 0, "The height of the desk on the preset, in meters")
+	presetAddCmd.Flags().BoolVarP(&deskPresetCurrent, "current", "c", false, "Use the current height of the desk")
+	presetAddCmd.Flags().StringVar(&deskPresetDescription, "description", "", "A description of the preset")
+	presetAddCmd.Flags().IntVar(&deskPresetOrder, "order", 0, "Position of the preset in listings")
+	presetAddCmd.MarkFlagsMutuallyExclusive("height", "current")
+
+	presetEditCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
+	presetEditCmd.Flags().Float32VarP(&deskPresetHeight, "height", "", 0, "The height of the desk on the preset")
+	presetEditCmd.Flags().StringVar(&deskPresetDescription, "description", "", "A description of the preset")
+	presetEditCmd.Flags().IntVar(&deskPresetOrder, "order", 0, "Position of the preset in listings, 0 to sort by height")
 
 	presetListCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
+	presetListCmd.Flags().StringVar(&deskPresetSort, "sort", string(config.SortByOrder), "Sort presets by order or height")
 	presetDeleteCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
+	presetRenameCmd.Flags().StringVarP(&deskFlag, "desk", "d", "", "The name of the desk")
 
 	presetCmd.AddCommand(presetAddCmd)
+	presetCmd.AddCommand(presetEditCmd)
+	presetCmd.AddCommand(presetRenameCmd)
 	presetCmd.AddCommand(presetDeleteCmd)
 	presetCmd.AddCommand(presetListCmd)
 	rootCmd.AddCommand(presetCmd)
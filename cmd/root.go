@@ -3,14 +3,18 @@ package cmd
 import (
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/history"
 	"github.com/spf13/cobra"
 )
 
 var (
 	cfgFile       string
 	configManager *config.ConfigManager
+	runHistory    *history.Store
 	rootCmd       = &cobra.Command{
 		Use:   "idasenctl",
 		Short: "A brief description of your application",
@@ -46,5 +50,7 @@ func initConfig() {
 	if err != nil {
 		panic(err)
 	}
+	cm.SetChangeSource(strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "))
 	configManager = cm
+	runHistory = history.NewStore(history.HistoryFile(cfgFile))
 }
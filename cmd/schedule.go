@@ -1,12 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"os"
+	"slices"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/idasen"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+	"github.com/samueltorres/idasenctl/internal/stats"
 	"github.com/samueltorres/idasenctl/internal/ui/schedulelist"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +24,27 @@ var (
 	schedulePreset   string
 	scheduleEnabled  bool
 	scheduleDays     []string
+	scheduleCron     string
+	scheduleInterval string
+	scheduleWindow   string
+	scheduleMissed   string
+	scheduleGrace    time.Duration
+	scheduleRetries  int
+	scheduleBackoff  time.Duration
+	scheduleCount    time.Duration
+	scheduleTimezone string
+	schedulePlan     string
+	scheduleJitter   time.Duration
+	scheduleHeight   float32
+	scheduleToggle   string
+	scheduleNotify   string
+	scheduleCommand  string
+
+	scheduleQuietHours string
+	scheduleMaxPerHour int
+
+	scheduleNextCount int
+	scheduleNextDesk  string
 )
 
 var scheduleCmd = &cobra.Command{
@@ -28,8 +56,33 @@ var scheduleCmd = &cobra.Command{
 var scheduleAddCmd = &cobra.Command{
 	Use:   "add [name]",
 	Short: "Add a new schedule",
-	Long:  `Add a new schedule for automated desk movements.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Add a new schedule for automated desk movements.
+
+A schedule runs either at --time on the given --days, or whenever the --cron
+expression matches. Cron expressions have five fields (minute, hour, day of
+month, month, day of week), for example:
+
+  */30 9-17 * * 1-5   every 30 minutes from 09:00 to 17:30 on weekdays
+  0 9 * * mon#1       09:00 on the first Monday of the month
+  0 10 * * fri-sun    10:00 from Friday to Sunday
+
+Steps restart every hour, so for a gap that does not divide an hour, like 45
+minutes, use an interval schedule instead.
+
+An interval schedule instead alternates between two presets inside a daily
+--window on the given --days, starting with the first one:
+
+  idasenctl schedule add cycle --interval sit=45m,stand=15m --window 9am-6pm --days mon-fri
+
+Instead of moving the desk to a --preset, a schedule can move it to a --height,
+--toggle it between a sitting and a standing preset, only --notify, or run a
+local --command:
+
+  idasenctl schedule add lunch --time 12:30 --days weekdays --height 0.75
+  idasenctl schedule add switch --cron "0 10-16/2 * * 1-5" --toggle sit,stand
+  idasenctl schedule add break --time 15:00 --days weekdays --notify "Go for a walk"
+  idasenctl schedule add lights --time 09:00 --days weekdays --command "hue scene focus"`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		scheduleName := args[0]
 
@@ -38,26 +91,88 @@ var scheduleAddCmd = &cobra.Command{
 			deskName = configManager.GetDefaultDesk()
 		}
 
-		days, err := parseDays(scheduleDays)
+		days, err := config.ParseDays(scheduleDays)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		missedPolicy, err := config.ParseMissedPolicy(scheduleMissed)
 		if err != nil {
 			log.Fatal(err)
 		}
 
 		schedule := config.Schedule{
-			Name:       scheduleName,
-			Time:       scheduleTime,
-			DeskName:   deskName,
-			PresetName: schedulePreset,
-			Enabled:    scheduleEnabled,
-			Days:       days,
+			Name:         scheduleName,
+			DeskName:     deskName,
+			Enabled:      scheduleEnabled,
+			Days:         days,
+			MissedPolicy: missedPolicy,
+			GracePeriod:  scheduleGrace,
+			Retries:      scheduleRetries,
+			RetryBackoff: scheduleBackoff,
+			Countdown:    scheduleCount,
+			Timezone:     timezoneName(scheduleTimezone),
+			Plan:         schedulePlan,
+			Jitter:       scheduleJitter,
+		}
+
+		switch {
+		case scheduleInterval != "":
+			if scheduleWindow == "" || len(days) == 0 {
+				log.Fatal("--interval requires --window and --days")
+			}
+			interval, err := parseInterval(scheduleInterval, scheduleWindow)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.Kind = config.KindInterval
+			schedule.Interval = interval
+		case scheduleCron != "":
+			_, err := scheduler.ParseCron(scheduleCron)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.Cron = scheduleCron
+			schedule.PresetName = schedulePreset
+		default:
+			if scheduleTime == "" || len(days) == 0 {
+				log.Fatal("either --cron or both --time and --days are required")
+			}
+			schedule.Time, err = config.ParseTimeOfDay(scheduleTime)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.PresetName = schedulePreset
+		}
+
+		if scheduleRetries < 0 {
+			log.Fatal("--retries must not be negative")
+		}
+		if scheduleCount < 0 {
+			log.Fatal("--countdown must not be negative")
+		}
+
+		schedule.Action, err = parseAction(cmd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if schedule.Kind != config.KindInterval && schedule.Action == nil && schedule.PresetName == "" {
+			log.Fatal("one of --preset, --height, --toggle, --notify or --command is required")
+		}
+
+		err = validateSchedule(&schedule)
+		if err != nil {
+			log.Fatal(err)
 		}
 
 		err = configManager.AddSchedule(schedule)
 		if err != nil {
 			log.Fatal(err)
 		}
+		reloadDaemon()
 
 		fmt.Printf("Schedule '%s' added successfully\n", scheduleName)
+		warnConflicts(schedule)
 	},
 }
 
@@ -86,77 +201,599 @@ var scheduleRemoveCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal(err)
 		}
+		reloadDaemon()
 
 		fmt.Printf("Schedule '%s' removed successfully\n", scheduleName)
 	},
 }
 
-func parseDays(dayStrings []string) ([]int, error) {
-	dayMap := map[string]int{
-		"sunday":    0,
-		"monday":    1,
-		"tuesday":   2,
-		"wednesday": 3,
-		"thursday":  4,
-		"friday":    5,
-		"saturday":  6,
-		"sun":       0,
-		"mon":       1,
-		"tue":       2,
-		"wed":       3,
-		"thu":       4,
-		"fri":       5,
-		"sat":       6,
-	}
-
-	var days []int
-	for _, dayStr := range dayStrings {
-		dayStr = strings.ToLower(dayStr)
-
-		if dayNum, err := strconv.Atoi(dayStr); err == nil {
-			if dayNum >= 0 && dayNum <= 6 {
-				days = append(days, dayNum)
-				continue
+var scheduleNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "List the upcoming scheduled moves",
+	Long: `List the upcoming moves of all enabled schedules, computed the same way the
+daemon computes them, and mark the ones that move a desk in the same minute as
+another schedule.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if scheduleNextCount <= 0 {
+			log.Fatal("--count must be positive")
+		}
+
+		var schedules []config.Schedule
+		for _, schedule := range configManager.GetEffectiveSchedules() {
+			if scheduleNextDesk == "" || schedule.DeskName == scheduleNextDesk {
+				schedules = append(schedules, schedule)
+			}
+		}
+
+		now := time.Now()
+		events := scheduler.Upcoming(schedules, now, scheduleNextCount)
+		if len(events) == 0 {
+			fmt.Println("No upcoming scheduled moves")
+			return
+		}
+
+		type slot struct {
+			desk   string
+			minute time.Time
+		}
+		conflicts := make(map[slot][]string)
+		for _, conflict := range scheduler.FindConflicts(configManager.GetEffectiveSchedules(), now, events[len(events)-1].At) {
+			conflicts[slot{conflict.Desk, conflict.At}] = conflict.Schedules
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tIN\tSCHEDULE\tDESK\tACTION\tNOTE")
+		for _, event := range events {
+			var others []string
+			for _, name := range conflicts[slot{event.Schedule.DeskName, event.At.Truncate(time.Minute)}] {
+				if name != event.Schedule.Name {
+					others = append(others, name)
+				}
+			}
+			note := ""
+			if len(others) > 0 {
+				note = "conflicts with " + strings.Join(others, ", ")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", event.At.Format("Mon 2006-01-02 15:04"), stats.FormatDuration(event.At.Sub(now)),
+				event.Schedule.Name, event.Schedule.DeskName, event.Describe(), note)
+		}
+		w.Flush()
+	},
+}
+
+var scheduleEnableCmd = &cobra.Command{
+	Use:   "enable [name]",
+	Short: "Enable a schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		schedule := setScheduleEnabled(args[0], true)
+		fmt.Printf("Schedule '%s' enabled\n", args[0])
+		warnConflicts(schedule)
+	},
+}
+
+var scheduleDisableCmd = &cobra.Command{
+	Use:   "disable [name]",
+	Short: "Disable a schedule",
+	Long:  `Disable a schedule without removing it, so it can be enabled again later.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setScheduleEnabled(args[0], false)
+		fmt.Printf("Schedule '%s' disabled\n", args[0])
+	},
+}
+
+func setScheduleEnabled(name string, enabled bool) config.Schedule {
+	schedule, err := configManager.GetSchedule(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if enabled {
+		err = validateSchedule(&schedule)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	schedule.Enabled = enabled
+	err = configManager.UpdateSchedule(name, schedule)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reloadDaemon()
+	return schedule
+}
+
+var scheduleEditCmd = &cobra.Command{
+	Use:   "edit [name]",
+	Short: "Change the settings of a schedule",
+	Long: `Change the given settings of a schedule, keeping all others.
+
+Setting --cron replaces the time and days of a schedule, and setting --time
+replaces its cron expression. Interval schedules are changed with --interval
+and --window instead of --time, --cron and --preset:
+
+  idasenctl schedule edit morning-stand --time 09:30 --preset stand
+  idasenctl schedule edit cycle --interval sit=50m,stand=10m`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scheduleName := args[0]
+		schedule, err := configManager.GetSchedule(scheduleName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		flags := cmd.Flags()
+		switch schedule.Kind {
+		case config.KindOnce:
+			if flags.Changed("time") || flags.Changed("days") || flags.Changed("cron") || flags.Changed("interval") || flags.Changed("window") {
+				log.Fatal("the time of a one-off schedule cannot be changed, remove it and add it again with 'idasenctl at'")
+			}
+		case config.KindInterval:
+			if flags.Changed("time") || flags.Changed("cron") || flags.Changed("preset") || changedAction(cmd) {
+				log.Fatal("--time, --cron and the action flags do not apply to interval schedules, use --interval and --window")
+			}
+		default:
+			if flags.Changed("interval") || flags.Changed("window") {
+				log.Fatal("--interval and --window only apply to interval schedules")
+			}
+		}
+
+		if flags.Changed("desk") {
+			schedule.DeskName = scheduleDeskName
+		}
+		if flags.Changed("days") {
+			schedule.Days, err = config.ParseDays(scheduleDays)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if flags.Changed("time") {
+			schedule.Time, err = config.ParseTimeOfDay(scheduleTime)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.Cron = ""
+		}
+		if flags.Changed("cron") {
+			_, err := scheduler.ParseCron(scheduleCron)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.Cron = scheduleCron
+			schedule.Time = ""
+			schedule.Days = nil
+		}
+		if flags.Changed("preset") {
+			schedule.PresetName = schedulePreset
+			schedule.Action = nil
+		}
+		if changedAction(cmd) {
+			schedule.Action, err = parseAction(cmd)
+			if err != nil {
+				log.Fatal(err)
+			}
+			schedule.PresetName = ""
+		}
+
+		if flags.Changed("interval") || flags.Changed("window") {
+			current := schedule.Interval
+			phases := fmt.Sprintf("%s=%s,%s=%s", current.FirstPreset, current.FirstDuration, current.SecondPreset, current.SecondDuration)
+			if flags.Changed("interval") {
+				phases = scheduleInterval
+			}
+			window := current.Start + "-" + current.End
+			if flags.Changed("window") {
+				window = scheduleWindow
+			}
+
+			schedule.Interval, err = parseInterval(phases, window)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if flags.Changed("missed") {
+			schedule.MissedPolicy, err = config.ParseMissedPolicy(scheduleMissed)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if flags.Changed("grace") {
+			schedule.GracePeriod = scheduleGrace
+		}
+		if flags.Changed("retries") {
+			if scheduleRetries < 0 {
+				log.Fatal("--retries must not be negative")
+			}
+			schedule.Retries = scheduleRetries
+		}
+		if flags.Changed("retry-backoff") {
+			schedule.RetryBackoff = scheduleBackoff
+		}
+		if flags.Changed("countdown") {
+			if scheduleCount < 0 {
+				log.Fatal("--countdown must not be negative")
+			}
+			schedule.Countdown = scheduleCount
+		}
+		if flags.Changed("timezone") {
+			schedule.Timezone = timezoneName(scheduleTimezone)
+		}
+		if flags.Changed("plan") {
+			schedule.Plan = schedulePlan
+		}
+		if flags.Changed("jitter") {
+			schedule.Jitter = scheduleJitter
+		}
+
+		err = validateSchedule(&schedule)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = configManager.UpdateSchedule(scheduleName, schedule)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Schedule '%s' updated successfully\n", scheduleName)
+		warnConflicts(schedule)
+	},
+}
+
+var scheduleRenameCmd = &cobra.Command{
+	Use:   "rename [name] [new-name]",
+	Short: "Rename a schedule",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := configManager.RenameSchedule(args[0], args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		fmt.Printf("Schedule '%s' renamed to '%s'\n", args[0], args[1])
+	},
+}
+
+var scheduleTimezoneCmd = &cobra.Command{
+	Use:   "timezone [zone]",
+	Short: "Show or set the time zone of schedules",
+	Long: `Show or set the IANA time zone, such as Europe/Lisbon, of the schedules
+without a --timezone of their own. "local" uses the system's time zone again,
+which is the default.
+
+Around daylight saving time changes, a time skipped when the clocks go forward
+runs at the moment of the change, and a time that occurs twice when they go
+back runs at its first occurrence.
+
+  idasenctl schedule timezone Europe/Lisbon`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			timezone := configManager.GetTimezone()
+			if timezone == "" {
+				zone, _ := time.Now().Zone()
+				timezone = fmt.Sprintf("local (%s)", zone)
 			}
+			fmt.Printf("Schedules run in time zone %s\n", timezone)
+			return
+		}
+
+		timezone := timezoneName(args[0])
+		err := configManager.SetTimezone(timezone)
+		if err != nil {
+			log.Fatal(err)
+		}
+		reloadDaemon()
+
+		if timezone == "" {
+			fmt.Println("Schedules run in the system's time zone")
+			return
 		}
+		fmt.Printf("Schedules run in time zone %s\n", timezone)
+	},
+}
+
+var scheduleLimitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Show or set the limits of automatic moves",
+	Long: `Show or set the limits of the moves made by schedules and by the standing
+goal. During the quiet hours, in the global time zone, the desks are not moved
+automatically, and each desk is moved automatically at most --max-per-hour
+times in any hour. Moves left out are recorded as skipped in the history.
 
-		if dayNum, ok := dayMap[dayStr]; ok {
-			days = append(days, dayNum)
+  idasenctl schedule limits --quiet-hours 22:00-07:00 --max-per-hour 4
+  idasenctl schedule limits --quiet-hours off --max-per-hour 0`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		limits := configManager.GetLimits()
+
+		if flags.Changed("quiet-hours") {
+			limits.QuietStart, limits.QuietEnd = "", ""
+			if !strings.EqualFold(scheduleQuietHours, "off") {
+				start, end, err := config.ParseTimeRange(scheduleQuietHours)
+				if err != nil {
+					log.Fatal(err)
+				}
+				limits.QuietStart, limits.QuietEnd = start, end
+			}
+		}
+		if flags.Changed("max-per-hour") {
+			limits.MaxMovesPerHour = scheduleMaxPerHour
+		}
+
+		if flags.Changed("quiet-hours") || flags.Changed("max-per-hour") {
+			err := configManager.SetLimits(limits)
+			if err != nil {
+				log.Fatal(err)
+			}
+			reloadDaemon()
+		}
+
+		if limits.QuietStart == "" {
+			fmt.Println("Quiet hours: none")
 		} else {
-			return nil, fmt.Errorf("invalid day: %s", dayStr)
+			fmt.Printf("Quiet hours: %s-%s\n", limits.QuietStart, limits.QuietEnd)
 		}
+		if limits.MaxMovesPerHour == 0 {
+			fmt.Println("Moves per hour: unlimited")
+		} else {
+			fmt.Printf("Moves per hour: at most %d per desk\n", limits.MaxMovesPerHour)
+		}
+	},
+}
+
+// actionFlags are the flags that set an action other than moving to a
+// preset.
+var actionFlags = []string{"height", "toggle", "notify", "command"}
+
+func changedAction(cmd *cobra.Command) bool {
+	return slices.ContainsFunc(actionFlags, cmd.Flags().Changed)
+}
+
+// parseAction returns the action set with one of the actionFlags, nil when
+// none is set.
+func parseAction(cmd *cobra.Command) (*config.Action, error) {
+	flags := cmd.Flags()
+	switch {
+	case flags.Changed("height"):
+		return &config.Action{Kind: config.ActionHeight, Height: scheduleHeight}, nil
+	case flags.Changed("toggle"):
+		sit, stand, ok := strings.Cut(scheduleToggle, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid --toggle %q, expected a sitting and a standing preset like sit,stand", scheduleToggle)
+		}
+		return &config.Action{
+			Kind:        config.ActionToggle,
+			SitPreset:   strings.TrimSpace(sit),
+			StandPreset: strings.TrimSpace(stand),
+		}, nil
+	case flags.Changed("notify"):
+		return &config.Action{Kind: config.ActionNotify, Message: scheduleNotify}, nil
+	case flags.Changed("command"):
+		return &config.Action{Kind: config.ActionCommand, Command: scheduleCommand}, nil
 	}
+	return nil, nil
+}
 
-	return days, nil
+// timezoneName returns the time zone to store for name, empty for "local",
+// the system's time zone.
+func timezoneName(name string) string {
+	if strings.EqualFold(name, "local") {
+		return ""
+	}
+	return name
 }
 
-func formatDays(days []int) string {
-	dayNames := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-	var dayStrings []string
+// conflictHorizon is how far ahead schedules are compared for conflicts,
+// long enough for monthly cron expressions to meet weekly schedules.
+const conflictHorizon = 35 * 24 * time.Hour
+
+// validateSchedule checks that the schedule is complete and that its desk
+// and presets exist. Preset names are replaced by the names they resolve to.
+func validateSchedule(schedule *config.Schedule) error {
+	err := scheduler.Validate(*schedule)
+	if err != nil {
+		return err
+	}
+
+	desk, err := configManager.GetDesk(schedule.DeskName)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, schedule.DeskName)
+	}
+
+	return validateScheduleOnDesk(schedule, desk)
+}
 
-	for _, day := range days {
-		if day >= 0 && day <= 6 {
-			dayStrings = append(dayStrings, dayNames[day])
+// validateScheduleOnDesk checks that the presets of the schedule exist on
+// the desk and that the schedule runs at all. Preset names are replaced by
+// the names they resolve to.
+func validateScheduleOnDesk(schedule *config.Schedule, desk config.Desk) error {
+	presetNames := []*string{&schedule.PresetName}
+	switch {
+	case schedule.Kind == config.KindInterval:
+		// The interval is shared with the loaded config until it is stored.
+		interval := *schedule.Interval
+		schedule.Interval = &interval
+		presetNames = []*string{&interval.FirstPreset, &interval.SecondPreset}
+	case schedule.ActionKind() == config.ActionToggle:
+		action := *schedule.Action
+		schedule.Action = &action
+		presetNames = []*string{&action.SitPreset, &action.StandPreset}
+	case schedule.ActionKind() == config.ActionHeight:
+		err := idasen.ValidateHeight(schedule.Action.Height)
+		if err != nil {
+			return err
+		}
+		presetNames = nil
+	case !schedule.MovesDesk():
+		presetNames = nil
+	}
+	for _, name := range presetNames {
+		preset, err := config.ResolvePreset(desk, *name)
+		if err != nil {
+			return err
 		}
+		*name = preset.Name
 	}
 
-	return strings.Join(dayStrings, ",")
+	_, err := scheduler.Next(*schedule, time.Now())
+	if errors.Is(err, scheduler.ErrNoNextRun) {
+		return fmt.Errorf("schedule '%s' never runs", schedule.Name)
+	}
+	return err
+}
+
+// warnConflicts prints the other schedules that move the desk of the
+// schedule in the same minute, once per schedule with the first time.
+func warnConflicts(schedule config.Schedule) {
+	if !schedule.Enabled {
+		return
+	}
+
+	now := time.Now()
+	reported := make(map[string]bool)
+	for _, conflict := range scheduler.FindConflicts(configManager.GetEffectiveSchedules(), now, now.Add(conflictHorizon)) {
+		if !slices.Contains(conflict.Schedules, schedule.Name) {
+			continue
+		}
+		for _, other := range conflict.Schedules {
+			if other == schedule.Name || reported[other] {
+				continue
+			}
+			reported[other] = true
+			fmt.Printf("Warning: schedule '%s' also moves desk %s at %s\n",
+				other, conflict.Desk, conflict.At.Format("Mon 2006-01-02 15:04"))
+		}
+	}
+}
+
+// parseInterval parses presets like "sit=45m,stand=15m" and a window like
+// "09:00-18:00" into an interval schedule.
+func parseInterval(phases string, window string) (*config.IntervalSchedule, error) {
+	parts := strings.Split(phases, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid interval %q, expected two presets like sit=45m,stand=15m", phases)
+	}
+
+	names := make([]string, 2)
+	durations := make([]time.Duration, 2)
+	for i, part := range parts {
+		name, duration, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid interval phase %q, expected preset=duration", part)
+		}
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval phase %q: %w", part, err)
+		}
+		names[i], durations[i] = name, d
+	}
+
+	start, end, err := config.ParseTimeRange(window)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := &config.IntervalSchedule{
+		FirstPreset:    names[0],
+		FirstDuration:  durations[0],
+		SecondPreset:   names[1],
+		SecondDuration: durations[1],
+		Start:          start,
+		End:            end,
+	}
+
+	return interval, scheduler.ValidateInterval(interval)
 }
 
 func init() {
-	scheduleAddCmd.Flags().StringVarP(&scheduleTime, "time", "t", "", "Time in HH:MM format (required)")
+	scheduleAddCmd.Flags().StringVarP(&scheduleTime, "time", "t", "", "Time of day (e.g., 09:00, 9am or 2:30pm)")
 	scheduleAddCmd.Flags().StringVarP(&scheduleDeskName, "desk", "d", "", "Desk name (defaults to default desk)")
-	scheduleAddCmd.Flags().StringVarP(&schedulePreset, "preset", "p", "", "Preset name (required)")
+	scheduleAddCmd.Flags().StringVarP(&schedulePreset, "preset", "p", "", "Preset name (required unless --interval or another action)")
 	scheduleAddCmd.Flags().BoolVarP(&scheduleEnabled, "enabled", "e", true, "Enable the schedule")
-	scheduleAddCmd.Flags().StringSliceVar(&scheduleDays, "days", []string{}, "Days of the week (e.g., monday,tuesday or 1,2 or mon,tue)")
+	scheduleAddCmd.Flags().StringSliceVar(&scheduleDays, "days", []string{}, "Days of the week (e.g., mon-fri, weekdays, weekends, monday,tuesday or 1,2)")
+
+	scheduleAddCmd.Flags().StringVar(&scheduleCron, "cron", "", "Cron expression, instead of --time and --days (e.g., \"*/30 9-17 * * 1-5\")")
+	scheduleAddCmd.Flags().StringVar(&scheduleInterval, "interval", "", "Alternate between two presets (e.g., sit=45m,stand=15m)")
+	scheduleAddCmd.Flags().StringVar(&scheduleWindow, "window", "", "Daily window of an interval schedule (e.g., 09:00-18:00 or 9am-6pm)")
+
+	scheduleAddCmd.Flags().StringVar(&scheduleMissed, "missed", string(config.MissedSkip), "What to do with runs missed while suspended or stopped: skip, latest or notify")
+	scheduleAddCmd.Flags().DurationVar(&scheduleGrace, "grace", 0, "How late a missed run may still be run with --missed latest (default 15m)")
+
+	scheduleAddCmd.Flags().IntVar(&scheduleRetries, "retries", 0, "How often to retry a failed move")
+	scheduleAddCmd.Flags().DurationVar(&scheduleBackoff, "retry-backoff", 0, "Delay before the first retry, doubled for each next one (default 30s)")
+
+	scheduleAddCmd.Flags().DurationVar(&scheduleCount, "countdown", 0, "Warning ahead of each move, during which it can be cancelled or snoozed (default 10s)")
+
+	scheduleAddCmd.Flags().StringVar(&scheduleTimezone, "timezone", "", "IANA time zone of the schedule's times (e.g., Europe/Lisbon, defaults to the global time zone)")
+
+	scheduleAddCmd.Flags().StringVar(&schedulePlan, "plan", "", "Only run while this plan is in use (e.g., wfh or office)")
+
+	scheduleAddCmd.Flags().DurationVar(&scheduleJitter, "jitter", 0, "Move each run by a random offset of up to this much earlier or later (e.g., 5m)")
+
+	scheduleAddCmd.Flags().Float32Var(&scheduleHeight, "height", 0, "Move to this height, in meters, instead of a preset")
+	scheduleAddCmd.Flags().StringVar(&scheduleToggle, "toggle", "", "Move a standing desk to the first preset and a sitting one to the second (e.g., sit,stand)")
+	scheduleAddCmd.Flags().StringVar(&scheduleNotify, "notify", "", "Only send this notification, such as a break reminder, instead of moving")
+	scheduleAddCmd.Flags().StringVar(&scheduleCommand, "command", "", "Run this local command with sh instead of moving")
+
+	scheduleAddCmd.MarkFlagsMutuallyExclusive("cron", "time")
+	scheduleAddCmd.MarkFlagsMutuallyExclusive("cron", "days")
+	scheduleAddCmd.MarkFlagsMutuallyExclusive("interval", "cron")
+	scheduleAddCmd.MarkFlagsMutuallyExclusive("interval", "time")
+	scheduleAddCmd.MarkFlagsMutuallyExclusive(append([]string{"interval", "preset"}, actionFlags...)...)
+
+	scheduleNextCmd.Flags().IntVarP(&scheduleNextCount, "count", "n", 10, "Number of moves to list")
+	scheduleNextCmd.Flags().StringVarP(&scheduleNextDesk, "desk", "d", "", "Only list the moves of this desk")
+
+	scheduleEditCmd.Flags().StringVarP(&scheduleTime, "time", "t", "", "Time of day (e.g., 09:00, 9am or 2:30pm)")
+	scheduleEditCmd.Flags().StringVarP(&scheduleDeskName, "desk", "d", "", "Desk name")
+	scheduleEditCmd.Flags().StringVarP(&schedulePreset, "preset", "p", "", "Preset name")
+	scheduleEditCmd.Flags().StringSliceVar(&scheduleDays, "days", []string{}, "Days of the week (e.g., mon-fri, weekdays, weekends, monday,tuesday or 1,2)")
+	scheduleEditCmd.Flags().StringVar(&scheduleCron, "cron", "", "Cron expression, replacing the time and days")
+	scheduleEditCmd.Flags().StringVar(&scheduleInterval, "interval", "", "Presets of an interval schedule (e.g., sit=45m,stand=15m)")
+	scheduleEditCmd.Flags().StringVar(&scheduleWindow, "window", "", "Daily window of an interval schedule (e.g., 09:00-18:00 or 9am-6pm)")
+	scheduleEditCmd.Flags().StringVar(&scheduleMissed, "missed", "", "What to do with missed runs: skip, latest or notify")
+	scheduleEditCmd.Flags().DurationVar(&scheduleGrace, "grace", 0, "How late a missed run may still be run with --missed latest")
+	scheduleEditCmd.Flags().IntVar(&scheduleRetries, "retries", 0, "How often to retry a failed move")
+	scheduleEditCmd.Flags().DurationVar(&scheduleBackoff, "retry-backoff", 0, "Delay before the first retry, doubled for each next one")
+	scheduleEditCmd.Flags().DurationVar(&scheduleCount, "countdown", 0, "Warning ahead of each move, during which it can be cancelled or snoozed")
+
+	scheduleEditCmd.Flags().StringVar(&scheduleTimezone, "timezone", "", "IANA time zone of the schedule's times, empty for the global time zone")
+
+	scheduleEditCmd.Flags().StringVar(&schedulePlan, "plan", "", "Only run while this plan is in use, empty to always run")
+
+	scheduleEditCmd.Flags().DurationVar(&scheduleJitter, "jitter", 0, "Move each run by a random offset of up to this much earlier or later, 0 for none")
+
+	scheduleLimitsCmd.Flags().StringVar(&scheduleQuietHours, "quiet-hours", "", "No automatic moves during these hours (e.g., 22:00-07:00 or 10pm-7am), off for none")
+	scheduleLimitsCmd.Flags().IntVar(&scheduleMaxPerHour, "max-per-hour", 0, "Most automatic moves of each desk in any hour, 0 for unlimited")
+
+	scheduleEditCmd.Flags().Float32Var(&scheduleHeight, "height", 0, "Move to this height, in meters, instead of a preset")
+	scheduleEditCmd.Flags().StringVar(&scheduleToggle, "toggle", "", "Move a standing desk to the first preset and a sitting one to the second (e.g., sit,stand)")
+	scheduleEditCmd.Flags().StringVar(&scheduleNotify, "notify", "", "Only send this notification, such as a break reminder, instead of moving")
+	scheduleEditCmd.Flags().StringVar(&scheduleCommand, "command", "", "Run this local command with sh instead of moving")
 
-	scheduleAddCmd.MarkFlagRequired("time")
-	scheduleAddCmd.MarkFlagRequired("preset")
-	scheduleAddCmd.MarkFlagRequired("days")
+	scheduleEditCmd.MarkFlagsMutuallyExclusive("cron", "time")
+	scheduleEditCmd.MarkFlagsMutuallyExclusive(append([]string{"preset"}, actionFlags...)...)
+	scheduleEditCmd.MarkFlagsMutuallyExclusive("cron", "days")
 
 	scheduleCmd.AddCommand(scheduleAddCmd)
 	scheduleCmd.AddCommand(scheduleListCmd)
 	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleNextCmd)
+	scheduleCmd.AddCommand(scheduleEnableCmd)
+	scheduleCmd.AddCommand(scheduleDisableCmd)
+	scheduleCmd.AddCommand(scheduleEditCmd)
+	scheduleCmd.AddCommand(scheduleRenameCmd)
+	scheduleCmd.AddCommand(scheduleTimezoneCmd)
+	scheduleCmd.AddCommand(scheduleLimitsCmd)
 
 	rootCmd.AddCommand(scheduleCmd)
 }
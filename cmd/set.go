@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"log"
+	"math"
+	"time"
 
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/history"
 	"github.com/samueltorres/idasenctl/internal/idasen"
 	"github.com/samueltorres/idasenctl/internal/ui/deskmove"
 	"github.com/spf13/cobra"
@@ -24,20 +30,41 @@ var setCmd = &cobra.Command{
 			panic(err)
 		}
 
-		preset, ok := desk.Presets[presetName]
-		if !ok {
-			log.Fatal("Could not find that preset")
+		preset, err := config.ResolvePreset(desk, presetName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// A running daemon is already connected, or connects and records
+		// the move itself.
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		err = moveWithDaemon(client, desk, preset)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, control.ErrDaemonNotRunning) {
+			log.Fatal(err)
+		}
+
+		record := history.Record{
+			Time:    time.Now(),
+			Desk:    desk.Name,
+			Trigger: history.TriggerCLI,
+			Preset:  preset.Name,
 		}
 
 		controller, err := idasen.NewController(desk.Address)
 		if err != nil {
+			recordFailedMove(record, err)
 			log.Fatal(err)
 		}
 
 		currentHeight, err := controller.GetCurrentHeight()
 		if err != nil {
+			recordFailedMove(record, err)
 			log.Fatal(err)
 		}
+		record.FromHeight = currentHeight
 
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -45,9 +72,12 @@ var setCmd = &cobra.Command{
 		updates := make(chan float32)
 		deskMoveProgram := deskmove.NewProgram(preset.Height, currentHeight, updates)
 
+		done := make(chan struct{})
 		go func() {
-			err = controller.MoveTo(ctx, preset.Height, updates)
+			defer close(done)
+			err := controller.MoveTo(ctx, preset.Height, updates)
 			if err != nil {
+				recordFailedMove(record, err)
 				log.Fatal(err)
 			}
 		}()
@@ -56,9 +86,70 @@ var setCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal(err)
 		}
+
+		// Quitting the progress view before the desk arrived stops the move.
+		cancel()
+		<-done
+
+		record.Outcome = history.OutcomeSuccess
+		record.DurationMs = time.Since(record.Time).Milliseconds()
+		record.ToHeight, err = controller.GetCurrentHeight()
+		if err == nil && math.Abs(float64(record.ToHeight-preset.Height)) >= 0.005 {
+			record.Outcome = history.OutcomeCancelled
+		}
+		recordMove(record)
 	},
 }
 
+// moveWithDaemon asks the running daemon to move the desk while showing its
+// progress. It returns control.ErrDaemonNotRunning when there is no daemon.
+func moveWithDaemon(client *control.Client, desk config.Desk, preset config.Preset) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan float32)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Move(ctx, desk.Name, preset.Name, updates)
+		done <- err
+	}()
+
+	var initialHeight float32
+	select {
+	case initialHeight = <-updates:
+	case err := <-done:
+		return err
+	}
+
+	deskMoveProgram := deskmove.NewProgram(preset.Height, initialHeight, updates)
+
+	var moveErr error
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		moveErr = <-done
+		if moveErr == nil {
+			// Leave the finished progress bar on screen for a moment.
+			time.Sleep(time.Second)
+		}
+		deskMoveProgram.Quit()
+	}()
+
+	err := deskMoveProgram.Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Quitting the progress view before the desk arrived stops the move.
+	cancel()
+	<-finished
+
+	if moveErr != nil && !errors.Is(moveErr, context.Canceled) {
+		return moveErr
+	}
+	return nil
+}
+
 func init() {
 
 	rootCmd.AddCommand(setCmd)
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/spf13/cobra"
+)
+
+var snoozeSchedule string
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze [duration]",
+	Short: "Postpone a scheduled move during its countdown",
+	Long: `Postpone the scheduled moves the running daemon announced and that are still
+counting down, or only the one of --schedule, by the duration from now
+(default 10m). The move is announced again before it runs and can be
+cancelled or snoozed once more.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		duration := 10 * time.Minute
+		if len(args) == 1 {
+			var err error
+			duration, err = time.ParseDuration(args[0])
+			if err != nil || duration <= 0 {
+				log.Fatalf("invalid duration %q, must be positive like 10m or 1h", args[0])
+			}
+		}
+
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		countdowns, err := client.Snooze(snoozeSchedule, duration)
+		if errors.Is(err, control.ErrDaemonNotRunning) {
+			log.Fatal("the daemon is not running, start it with 'idasenctl daemon'")
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, countdown := range countdowns {
+			fmt.Printf("Snoozed schedule '%s' for desk %s until %s (%s)\n",
+				countdown.Schedule, countdown.Desk, countdown.At.Format("15:04"), countdown.Action)
+		}
+	},
+}
+
+func init() {
+	snoozeCmd.Flags().StringVarP(&snoozeSchedule, "schedule", "s", "", "Only snooze the move of this schedule")
+
+	rootCmd.AddCommand(snoozeCmd)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/stats"
+	"github.com/samueltorres/idasenctl/internal/ui/statsview"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsDesk   string
+	statsDays   int
+	statsMaxGap time.Duration
+	statsOutput string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show time spent sitting and standing",
+	Long: `Show the time spent sitting and standing per day and week, derived from the
+heights the daemon samples every few minutes and from recorded moves.
+
+The desk counts as standing from its standing height, which can be changed
+with 'idasenctl desk standing-height'. After each known height the desk is
+assumed to stay there for at most --max-gap; time after that is not counted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		deskName := statsDesk
+		if deskName == "" {
+			deskName = configManager.GetDefaultDesk()
+		}
+		desk, err := configManager.GetDesk(deskName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if statsDays < 1 {
+			log.Fatal("--days must be at least 1")
+		}
+
+		to := time.Now()
+		year, month, day := to.Date()
+		from := time.Date(year, month, day-statsDays+1, 0, 0, 0, 0, time.Local)
+
+		report, err := stats.Load(
+			runHistory,
+			history.NewSampleStore(history.SamplesFile(configManager.Path())),
+			desk, from, to, statsMaxGap,
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		output := statsOutput
+		if output == "" {
+			output = "text"
+			if isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd()) {
+				output = "ui"
+			}
+		}
+
+		switch output {
+		case "ui":
+			err = statsview.NewProgram(report).Run()
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "text":
+			fmt.Printf("Desk %s, standing from %.2f m\n\n", report.Desk, report.StandingHeight)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DAY\tSTANDING\tSITTING\tSTANDING %")
+			for _, day := range report.Days {
+				printTotals(w, day.Date.Format("Mon 2006-01-02"), day.Totals)
+			}
+			fmt.Fprintln(w, "\t\t\t")
+			fmt.Fprintln(w, "WEEK\tSTANDING\tSITTING\tSTANDING %")
+			for _, week := range report.Weeks {
+				printTotals(w, week.Start.Format("2006-01-02"), week.Totals)
+			}
+			w.Flush()
+		default:
+			log.Fatalf("invalid output format %q, must be ui, text or json", output)
+		}
+	},
+}
+
+func printTotals(w *tabwriter.Writer, label string, totals stats.Totals) {
+	if totals.Total() == 0 {
+		fmt.Fprintf(w, "%s\t-\t-\t-\n", label)
+		return
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\n", label,
+		stats.FormatDuration(totals.Standing), stats.FormatDuration(totals.Sitting), totals.StandingRatio()*100)
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsDesk, "desk", "d", "", "Desk name (defaults to default desk)")
+	statsCmd.Flags().IntVar(&statsDays, "days", 7, "Number of days to show, including today")
+	statsCmd.Flags().DurationVar(&statsMaxGap, "max-gap", stats.DefaultMaxGap, "How long the desk is assumed to stay at a height without a newer sample")
+	statsCmd.Flags().StringVarP(&statsOutput, "output", "o", "", "Output format: ui, text or json (defaults to ui in a terminal)")
+
+	rootCmd.AddCommand(statsCmd)
+}
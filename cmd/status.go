@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/spf13/cobra"
+)
+
+var statusOutput string
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the live state of the desks from the running daemon",
+	Long: `Show the connection state and the latest height of every desk as seen by the
+running daemon, without connecting to the desks, the scheduled moves that are
+counting down and whether the schedules are paused.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := control.NewClient(control.SocketFile(configManager.Path()))
+		status, err := client.Status()
+		if errors.Is(err, control.ErrDaemonNotRunning) {
+			log.Fatal("the daemon is not running, start it with 'idasenctl daemon'")
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		switch statusOutput {
+		case "json":
+			if status.Desks == nil {
+				status.Desks = []control.DeskState{}
+			}
+			if status.Countdowns == nil {
+				status.Countdowns = []control.Countdown{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(status)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "text":
+			if len(status.Desks) == 0 {
+				fmt.Println("No desks configured")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DESK\tCONNECTION\tHEIGHT\tPOSITION\tUPDATED")
+			for _, state := range status.Desks {
+				connection := "disconnected"
+				switch {
+				case state.Connected && state.Monitored:
+					connection = "monitored"
+				case state.Connected:
+					connection = "connected"
+				case state.Error != "":
+					connection = "error: " + state.Error
+				}
+
+				height, position, updated := "-", "-", "never"
+				if !state.UpdatedAt.IsZero() {
+					height = fmt.Sprintf("%.2f m", state.Height)
+					position = "sitting"
+					if desk, err := configManager.GetDesk(state.Desk); err == nil && state.Height >= desk.StandingThreshold() {
+						position = "standing"
+					}
+					updated = time.Since(state.UpdatedAt).Round(time.Second).String() + " ago"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", state.Desk, connection, height, position, updated)
+			}
+			w.Flush()
+			printCountdowns(status.Countdowns)
+			printPause(status.Paused)
+		default:
+			log.Fatalf("invalid output format %q, must be text or json", statusOutput)
+		}
+	},
+}
+
+func printCountdowns(countdowns []control.Countdown) {
+	if len(countdowns) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCHEDULE\tDESK\tACTION\tMOVES IN")
+	for _, countdown := range countdowns {
+		in := time.Until(countdown.At).Round(time.Second).String()
+		if countdown.Snoozed {
+			in += " (snoozed)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", countdown.Schedule, countdown.Desk, countdown.Action, in)
+	}
+	w.Flush()
+}
+
+func printPause(pause *control.Pause) {
+	if pause == nil {
+		return
+	}
+
+	fmt.Println()
+	if pause.Until.IsZero() {
+		fmt.Println("Schedules are paused until resumed")
+		return
+	}
+	fmt.Printf("Schedules are paused until %s\n", pause.Until.Format("Mon 2006-01-02 15:04"))
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "text", "Output format: text or json")
+
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,38 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write writes to a temporary file in the same directory and renames it
+// over path, so a crash never leaves a truncated file behind.
+func Write(path string, b []byte, perm os.FileMode) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(f.Name(), perm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -71,6 +71,21 @@ func (a *Adapter) WriteCharacteristic(cUUID string, data []byte) error {
 	return nil
 }
 
+// Subscribe calls fn with the new value every time the characteristic
+// notifies a change.
+func (a *Adapter) Subscribe(cUUID string, fn func([]byte)) error {
+	c, err := a.getCharacteristic(cUUID)
+	if err != nil {
+		return ErrCharacteristicNotExists
+	}
+
+	return c.EnableNotifications(fn)
+}
+
+func (a *Adapter) Disconnect() error {
+	return a.device.Disconnect()
+}
+
 func (a *Adapter) getCharacteristic(cUUID string) (*bluetooth.DeviceCharacteristic, error) {
 	ds, err := a.device.DiscoverServices(nil)
 	if err != nil {
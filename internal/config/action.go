@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidAction = errors.New("invalid action")
+
+type ActionKind string
+
+const (
+	// ActionPreset moves the desk to the schedule's PresetName.
+	ActionPreset ActionKind = "preset"
+	// ActionHeight moves the desk to a height.
+	ActionHeight ActionKind = "height"
+	// ActionToggle moves a standing desk to the sitting preset and a
+	// sitting one to the standing preset.
+	ActionToggle ActionKind = "toggle"
+	// ActionNotify only sends a notification, such as a break reminder.
+	ActionNotify ActionKind = "notify"
+	// ActionCommand runs a local command.
+	ActionCommand ActionKind = "command"
+)
+
+func ParseActionKind(s string) (ActionKind, error) {
+	switch kind := ActionKind(strings.ToLower(s)); kind {
+	case ActionPreset, ActionHeight, ActionToggle, ActionNotify, ActionCommand:
+		return kind, nil
+	}
+	return "", fmt.Errorf("%w: unknown kind %q, must be one of preset, height, toggle, notify or command", ErrInvalidAction, s)
+}
+
+// Action is what a schedule does when it runs. Schedules without one move
+// the desk to their PresetName.
+type Action struct {
+	Kind        ActionKind `yaml:"kind"`
+	Height      float32    `yaml:"height,omitempty"`      // Only for ActionHeight, in meters
+	SitPreset   string     `yaml:"sitPreset,omitempty"`   // Only for ActionToggle
+	StandPreset string     `yaml:"standPreset,omitempty"` // Only for ActionToggle
+	Message     string     `yaml:"message,omitempty"`     // Only for ActionNotify, defaults to DefaultActionMessage
+	Command     string     `yaml:"command,omitempty"`     // Only for ActionCommand, run with sh -c
+}
+
+// DefaultActionMessage is the notification of ActionNotify without a
+// Message.
+const DefaultActionMessage = "Time for a break!"
+
+// String describes the action, such as "move to 1.10 m". Actions of kind
+// ActionPreset are described by their schedule, which holds the preset.
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionHeight:
+		return fmt.Sprintf("move to %.2f m", a.Height)
+	case ActionToggle:
+		return fmt.Sprintf("toggle between presets '%s' and '%s'", a.SitPreset, a.StandPreset)
+	case ActionNotify:
+		return fmt.Sprintf("notify %q", a.NotifyMessage())
+	case ActionCommand:
+		return fmt.Sprintf("run %q", a.Command)
+	}
+	return string(a.Kind)
+}
+
+// NotifyMessage returns the notification of an ActionNotify.
+func (a Action) NotifyMessage() string {
+	if a.Message != "" {
+		return a.Message
+	}
+	return DefaultActionMessage
+}
+
+// renamePreset replaces the presets of a toggle named like key, a
+// lowercase name, with newName.
+func (a *Action) renamePreset(key, newName string) {
+	if strings.ToLower(a.SitPreset) == key {
+		a.SitPreset = newName
+	}
+	if strings.ToLower(a.StandPreset) == key {
+		a.StandPreset = newName
+	}
+}
+
+// ActionKind returns what the schedule does when it runs.
+func (s Schedule) ActionKind() ActionKind {
+	if s.Action == nil {
+		return ActionPreset
+	}
+	return s.Action.Kind
+}
+
+// MovesDesk reports whether the schedule moves its desk when it runs.
+func (s Schedule) MovesDesk() bool {
+	switch s.ActionKind() {
+	case ActionNotify, ActionCommand:
+		return false
+	}
+	return true
+}
+
+// DescribeAction describes what a run of the schedule that moves the desk
+// to presetName, the preset of the run for interval schedules, does.
+func (s Schedule) DescribeAction(presetName string) string {
+	if s.ActionKind() == ActionPreset {
+		return fmt.Sprintf("move to preset '%s'", presetName)
+	}
+	return s.Action.String()
+}
+
+// Validate checks that the action has the parameters of its kind.
+func (a Action) Validate() error {
+	switch a.Kind {
+	case ActionPreset:
+	case ActionHeight:
+		if a.Height <= 0 {
+			return fmt.Errorf("%w: moving to a height needs a height", ErrInvalidAction)
+		}
+	case ActionToggle:
+		if a.SitPreset == "" || a.StandPreset == "" {
+			return fmt.Errorf("%w: toggling needs a sitting and a standing preset", ErrInvalidAction)
+		}
+	case ActionNotify:
+	case ActionCommand:
+		if strings.TrimSpace(a.Command) == "" {
+			return fmt.Errorf("%w: running a command needs a command", ErrInvalidAction)
+		}
+	default:
+		_, err := ParseActionKind(string(a.Kind))
+		return err
+	}
+	return nil
+}
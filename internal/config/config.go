@@ -2,46 +2,201 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/samueltorres/idasenctl/internal/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	ErrDeskNotExists = errors.New("desk not exists")
+	ErrDeskNotExists       = errors.New("desk not exists")
+	ErrPresetNotExists     = errors.New("preset not exists")
+	ErrPresetExists        = errors.New("preset already exists")
+	ErrInvalidConfig       = errors.New("invalid config")
+	ErrScheduleNotExists   = errors.New("schedule not found")
+	ErrScheduleExists      = errors.New("schedule already exists")
+	ErrInvalidMissedPolicy = errors.New("invalid missed policy, must be one of skip, latest or notify")
 )
 
 type Config struct {
 	Desks       map[string]Desk `yaml:"desks"`
 	DefaultDesk string          `yaml:"defaultDesk"`
 	Schedules   []Schedule      `yaml:"schedules"`
+	Goal        *Goal           `yaml:"goal,omitempty"`
+	Exclusions  []Exclusion     `yaml:"exclusions,omitempty"` // Days on which no schedule runs
+	Timezone    string          `yaml:"timezone,omitempty"`   // IANA time zone of schedules without their own, the system's when empty
+	Plans       Plans           `yaml:"plans,omitempty"`
+	Limits      Limits          `yaml:"limits,omitempty"`
 }
 
 type Desk struct {
 	Name    string            `yaml:"name"`
 	Address string            `yaml:"address"`
 	Presets map[string]Preset `yaml:"presets"`
+	// StandingHeight is the height from which the desk counts as standing
+	// in statistics.
+	StandingHeight float32 `yaml:"standingHeight,omitempty"`
+}
+
+// DefaultStandingHeight is used for desks without a StandingHeight.
+const DefaultStandingHeight float32 = 0.95
+
+// StandingThreshold returns the height from which the desk counts as
+// standing.
+func (d Desk) StandingThreshold() float32 {
+	if d.StandingHeight > 0 {
+		return d.StandingHeight
+	}
+	return DefaultStandingHeight
 }
 
 type Preset struct {
-	Name   string  `yaml:"name"`
-	Height float32 `yaml:"height"`
+	Name        string  `yaml:"name"`
+	Height      float32 `yaml:"height"`
+	Description string  `yaml:"description,omitempty"`
+	Order       int     `yaml:"order,omitempty"` // Position in listings, presets without one come last
+}
+
+type ScheduleKind string
+
+const (
+	// KindFixed moves the desk to a preset at a fixed time or cron expression.
+	KindFixed ScheduleKind = ""
+	// KindInterval alternates between two presets inside a daily window.
+	KindInterval ScheduleKind = "interval"
+	// KindOnce moves the desk to a preset once, at At. It is removed from the
+	// config file by the next change made once it expired.
+	KindOnce ScheduleKind = "once"
+)
+
+// MissedPolicy decides what the daemon does with runs it missed because the
+// computer was suspended or the daemon was not running.
+type MissedPolicy string
+
+const (
+	// MissedSkip drops missed runs.
+	MissedSkip MissedPolicy = "skip"
+	// MissedRunLatest runs the latest missed run if it is within the grace period.
+	MissedRunLatest MissedPolicy = "latest"
+	// MissedNotify only sends a notification about the latest missed run.
+	MissedNotify MissedPolicy = "notify"
+)
+
+// DefaultGracePeriod is how late a missed run may be under MissedRunLatest
+// when the schedule does not set its own grace period.
+const DefaultGracePeriod = 15 * time.Minute
+
+// DefaultRetryBackoff is the delay before the first retry of a failed
+// scheduled move when the schedule does not set its own.
+const DefaultRetryBackoff = 30 * time.Second
+
+// DefaultCountdown is how long before a scheduled move the warning is sent
+// when the schedule does not set its own countdown.
+const DefaultCountdown = 10 * time.Second
+
+func ParseMissedPolicy(s string) (MissedPolicy, error) {
+	switch MissedPolicy(strings.ToLower(s)) {
+	case "", MissedSkip:
+		return MissedSkip, nil
+	case MissedRunLatest:
+		return MissedRunLatest, nil
+	case MissedNotify:
+		return MissedNotify, nil
+	}
+	return "", ErrInvalidMissedPolicy
 }
 
 type Schedule struct {
-	Name       string `yaml:"name"`
-	Time       string `yaml:"time"`       // HH:MM format
-	DeskName   string `yaml:"deskName"`   // Which desk to move
-	PresetName string `yaml:"presetName"` // Which preset to move to
-	Enabled    bool   `yaml:"enabled"`    // Whether this schedule is active
-	Days       []int  `yaml:"days"`       // 0=Sunday, 1=Monday, ..., 6=Saturday
+	Name         string            `yaml:"name"`
+	Kind         ScheduleKind      `yaml:"kind,omitempty"`
+	Time         string            `yaml:"time"`                   // HH:MM format
+	DeskName     string            `yaml:"deskName"`               // Which desk to move
+	PresetName   string            `yaml:"presetName,omitempty"`   // Which preset to move to
+	Enabled      bool              `yaml:"enabled"`                // Whether this schedule is active
+	Days         []int             `yaml:"days"`                   // 0=Sunday, 1=Monday, ..., 6=Saturday
+	Cron         string            `yaml:"cron,omitempty"`         // Cron expression, replaces Time and Days when set
+	Interval     *IntervalSchedule `yaml:"interval,omitempty"`     // Only for KindInterval
+	MissedPolicy MissedPolicy      `yaml:"missedPolicy,omitempty"` // Defaults to MissedSkip
+	GracePeriod  time.Duration     `yaml:"gracePeriod,omitempty"`  // Defaults to DefaultGracePeriod
+	Retries      int               `yaml:"retries,omitempty"`      // Extra attempts after a failed move
+	RetryBackoff time.Duration     `yaml:"retryBackoff,omitempty"` // Delay before the first retry, doubled for each next one
+	Countdown    time.Duration     `yaml:"countdown,omitempty"`    // Warning ahead of a move, during which it can be cancelled or snoozed
+	Exclusions   []Exclusion       `yaml:"exclusions,omitempty"`   // Days on which the schedule does not run
+	Timezone     string            `yaml:"timezone,omitempty"`     // IANA time zone of Time, Cron and Interval, defaults to Config.Timezone
+	At           time.Time         `yaml:"at,omitempty"`           // Only for KindOnce
+	Plan         string            `yaml:"plan,omitempty"`         // Only runs while this plan is in use
+	Jitter       time.Duration     `yaml:"jitter,omitempty"`       // Runs up to Jitter before or after its time, at random
+	Action       *Action           `yaml:"action,omitempty"`       // What a run does, moving to PresetName when nil
+
+	// PlanDays are the weekdays on which Plan is in use, set on the
+	// schedules returned by GetEffectiveSchedules.
+	PlanDays []int `yaml:"-"`
+}
+
+// Expired reports whether the schedule is a one-off that can no longer run:
+// its time has passed by more than its grace period for missed runs.
+func (s Schedule) Expired(now time.Time) bool {
+	grace := s.GracePeriod
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+	return s.Kind == KindOnce && now.Sub(s.At) > grace
+}
+
+// CountdownDuration returns how long before a move of the schedule the
+// warning is sent.
+func (s Schedule) CountdownDuration() time.Duration {
+	if s.Countdown > 0 {
+		return s.Countdown
+	}
+	return DefaultCountdown
+}
+
+// IntervalSchedule alternates the desk between two presets, starting with
+// the first one at Start and stopping at End on every day in Days.
+type IntervalSchedule struct {
+	FirstPreset    string        `yaml:"firstPreset"`
+	FirstDuration  time.Duration `yaml:"firstDuration"`
+	SecondPreset   string        `yaml:"secondPreset"`
+	SecondDuration time.Duration `yaml:"secondDuration"`
+	Start          string        `yaml:"start"` // HH:MM format
+	End            string        `yaml:"end"`   // HH:MM format
+}
+
+// renamePreset replaces the presets named like key, a lowercase name, with
+// newName.
+func (i *IntervalSchedule) renamePreset(key, newName string) {
+	if strings.ToLower(i.FirstPreset) == key {
+		i.FirstPreset = newName
+	}
+	if strings.ToLower(i.SecondPreset) == key {
+		i.SecondPreset = newName
+	}
+}
+
+// usesPreset reports whether the schedule moves its desk to the preset with
+// the key, a lowercase name.
+func (s Schedule) usesPreset(key string) bool {
+	switch {
+	case s.Interval != nil:
+		return strings.ToLower(s.Interval.FirstPreset) == key || strings.ToLower(s.Interval.SecondPreset) == key
+	case s.ActionKind() == ActionToggle:
+		return strings.ToLower(s.Action.SitPreset) == key || strings.ToLower(s.Action.StandPreset) == key
+	case s.ActionKind() == ActionPreset:
+		return strings.ToLower(s.PresetName) == key
+	}
+	return false
 }
 
 type ConfigManager struct {
-	configFile string
-	config     *Config
+	configFile   string
+	config       *Config
+	changeSource string
 }
 
 func NewConfigManager(configFile string) (*ConfigManager, error) {
@@ -60,6 +215,11 @@ func NewConfigManager(configFile string) (*ConfigManager, error) {
 	}, nil
 }
 
+// Path returns the path of the config file.
+func (cm *ConfigManager) Path() string {
+	return cm.configFile
+}
+
 func (cm *ConfigManager) GetDesk(name string) (Desk, error) {
 	if d, ok := cm.config.Desks[name]; ok {
 		return d, nil
@@ -77,6 +237,17 @@ func (cm *ConfigManager) SetDesk(desk Desk) error {
 	return cm.storeConfig()
 }
 
+func (cm *ConfigManager) SetDeskStandingHeight(deskName string, height float32) error {
+	desk, ok := cm.config.Desks[deskName]
+	if !ok {
+		return ErrDeskNotExists
+	}
+
+	desk.StandingHeight = height
+	cm.config.Desks[deskName] = desk
+	return cm.storeConfig()
+}
+
 func (cm *ConfigManager) SetDefaultDesk(name string) error {
 	cm.config.DefaultDesk = name
 	return cm.storeConfig()
@@ -99,38 +270,143 @@ func (cm *ConfigManager) SetDeskPreset(deskName string, presetName string, heigh
 	if d.Presets == nil {
 		d.Presets = make(map[string]Preset)
 	}
-	d.Presets[strings.ToLower(presetName)] = Preset{
-		Name:   presetName,
-		Height: height,
+	key := strings.ToLower(presetName)
+	preset := d.Presets[key]
+	preset.Name = presetName
+	preset.Height = height
+	d.Presets[key] = preset
+
+	return cm.storeConfig()
+}
+
+// SaveDeskPreset adds a preset or replaces the one with the same name.
+func (cm *ConfigManager) SaveDeskPreset(deskName string, preset Preset) error {
+	d, ok := cm.config.Desks[deskName]
+	if !ok {
+		return ErrDeskNotExists
+	}
+
+	if d.Presets == nil {
+		d.Presets = make(map[string]Preset)
+		cm.config.Desks[deskName] = d
+	}
+	d.Presets[strings.ToLower(preset.Name)] = preset
+
+	return cm.storeConfig()
+}
+
+// RenameDeskPreset renames a preset and updates the schedules that use it.
+func (cm *ConfigManager) RenameDeskPreset(deskName string, oldName string, newName string) error {
+	d, ok := cm.config.Desks[deskName]
+	if !ok {
+		return ErrDeskNotExists
+	}
+
+	oldKey := strings.ToLower(oldName)
+	newKey := strings.ToLower(newName)
+	preset, ok := d.Presets[oldKey]
+	if !ok {
+		return ErrPresetNotExists
+	}
+	if _, exists := d.Presets[newKey]; exists && newKey != oldKey {
+		return ErrPresetExists
+	}
+
+	delete(d.Presets, oldKey)
+	preset.Name = newName
+	d.Presets[newKey] = preset
+
+	for i, schedule := range cm.config.Schedules {
+		if schedule.DeskName != deskName {
+			continue
+		}
+		if strings.ToLower(schedule.PresetName) == oldKey {
+			cm.config.Schedules[i].PresetName = newName
+		}
+		if schedule.Interval != nil {
+			schedule.Interval.renamePreset(oldKey, newName)
+		}
+		if schedule.Action != nil {
+			schedule.Action.renamePreset(oldKey, newName)
+		}
+	}
+	if cm.goalUsesPreset(deskName, oldKey) {
+		cm.config.Goal.Preset = newName
 	}
 
 	return cm.storeConfig()
 }
 
+// DeleteDeskPreset deletes a preset, unless schedules or the standing goal
+// still move the desk to it.
 func (cm *ConfigManager) DeleteDeskPreset(deskName string, presetName string) error {
 	d, ok := cm.config.Desks[deskName]
 	if !ok {
 		return ErrDeskNotExists
 	}
 
+	key := strings.ToLower(presetName)
+	var users []string
+	for _, schedule := range cm.config.Schedules {
+		if schedule.DeskName == deskName && schedule.usesPreset(key) {
+			users = append(users, fmt.Sprintf("schedule '%s'", schedule.Name))
+		}
+	}
+	if cm.goalUsesPreset(deskName, key) {
+		users = append(users, "the standing goal")
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w: '%s' is used by %s", ErrPresetInUse, presetName, strings.Join(users, ", "))
+	}
+
 	if d.Presets == nil {
 		d.Presets = make(map[string]Preset)
 	}
 
-	delete(d.Presets, strings.ToLower(presetName))
+	delete(d.Presets, key)
 
 	return cm.storeConfig()
 }
 
+// goalUsesPreset reports whether the standing goal moves the desk to the
+// preset with the key, a lowercase name.
+func (cm *ConfigManager) goalUsesPreset(deskName string, key string) bool {
+	goal := cm.config.Goal
+	if goal == nil || !goal.AutoMove || strings.ToLower(goal.Preset) != key {
+		return false
+	}
+	goalDesk := goal.DeskName
+	if goalDesk == "" {
+		goalDesk = cm.config.DefaultDesk
+	}
+	return goalDesk == deskName
+}
+
 func (cm *ConfigManager) storeConfig() error {
-	f, err := os.OpenFile(cm.configFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	return cm.writeConfig(cm.config)
+}
+
+// writeConfig validates cfg and saves it to the config file, leaving out the
+// expired one-off schedules.
+func (cm *ConfigManager) writeConfig(cfg *Config) error {
+	cfg.Schedules = unexpired(cfg.Schedules, time.Now())
+
+	err := cfg.Validate()
 	if err != nil {
-		return errors.Join(err, errors.New("could not open config file"))
+		return err
 	}
-	defer f.Close()
 
-	encoder := yaml.NewEncoder(f)
-	err = encoder.Encode(cm.config)
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save config file"))
+	}
+
+	err = cm.recordHistory(b)
+	if err != nil {
+		return err
+	}
+
+	err = atomicfile.Write(cm.configFile, b, 0644)
 	if err != nil {
 		return errors.Join(err, errors.New("could not save config file"))
 	}
@@ -138,11 +414,112 @@ func (cm *ConfigManager) storeConfig() error {
 	return nil
 }
 
+// unexpired returns the schedules that have not expired at now. It makes a
+// new slice, as the schedules may be shared with another config.
+func unexpired(schedules []Schedule, now time.Time) []Schedule {
+	var kept []Schedule
+	for _, schedule := range schedules {
+		if !schedule.Expired(now) {
+			kept = append(kept, schedule)
+		}
+	}
+	return kept
+}
+
+// Validate checks the structural invariants every stored config must hold.
+func (c *Config) Validate() error {
+	for key, desk := range c.Desks {
+		if key == "" || desk.Name != key {
+			return fmt.Errorf("%w: desk %q is stored under key %q", ErrInvalidConfig, desk.Name, key)
+		}
+		for presetKey, preset := range desk.Presets {
+			if preset.Name == "" || presetKey != strings.ToLower(preset.Name) {
+				return fmt.Errorf("%w: preset %q on desk %q is stored under key %q", ErrInvalidConfig, preset.Name, desk.Name, presetKey)
+			}
+		}
+	}
+
+	for _, schedule := range c.Schedules {
+		if schedule.Name == "" {
+			return fmt.Errorf("%w: schedule without a name", ErrInvalidConfig)
+		}
+		if _, err := LoadLocation(schedule.Timezone); err != nil {
+			return fmt.Errorf("%w: schedule %q: %w", ErrInvalidConfig, schedule.Name, err)
+		}
+		if err := ValidatePlanName(schedule.Plan); err != nil {
+			return fmt.Errorf("%w: schedule %q: %w", ErrInvalidConfig, schedule.Name, err)
+		}
+		for _, exclusion := range schedule.Exclusions {
+			err := exclusion.Validate()
+			if err != nil {
+				return fmt.Errorf("%w: schedule %q: %w", ErrInvalidConfig, schedule.Name, err)
+			}
+		}
+	}
+
+	if _, err := LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	if err := c.Plans.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Limits.validate(); err != nil {
+		return err
+	}
+
+	for _, exclusion := range c.Exclusions {
+		err := exclusion.Validate()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	return nil
+}
+
 func (cm *ConfigManager) GetSchedules() []Schedule {
 	return cm.config.Schedules
 }
 
+// GetEffectiveSchedules returns the schedules as they run, with the global
+// exclusions added to the exclusions of every schedule, the global time
+// zone set on those without their own, and those of plans that are not in
+// use disabled.
+func (cm *ConfigManager) GetEffectiveSchedules() []Schedule {
+	schedules := make([]Schedule, 0, len(cm.config.Schedules))
+	for _, schedule := range cm.config.Schedules {
+		if schedule.Plan != "" {
+			schedule.PlanDays = cm.config.Plans.planDays(schedule.Plan)
+			if len(schedule.PlanDays) == 0 {
+				schedule.Enabled = false
+			}
+		}
+		if len(cm.config.Exclusions) > 0 {
+			schedule.Exclusions = slices.Concat(schedule.Exclusions, cm.config.Exclusions)
+		}
+		if schedule.Timezone == "" {
+			schedule.Timezone = cm.config.Timezone
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules
+}
+
+func (cm *ConfigManager) GetSchedule(name string) (Schedule, error) {
+	for _, schedule := range cm.config.Schedules {
+		if schedule.Name == name {
+			return schedule, nil
+		}
+	}
+	return Schedule{}, ErrScheduleNotExists
+}
+
 func (cm *ConfigManager) AddSchedule(schedule Schedule) error {
+	if _, err := cm.GetSchedule(schedule.Name); err == nil {
+		return fmt.Errorf("%w: %s", ErrScheduleExists, schedule.Name)
+	}
 	cm.config.Schedules = append(cm.config.Schedules, schedule)
 	return cm.storeConfig()
 }
@@ -154,7 +531,7 @@ func (cm *ConfigManager) RemoveSchedule(name string) error {
 			return cm.storeConfig()
 		}
 	}
-	return errors.New("schedule not found")
+	return ErrScheduleNotExists
 }
 
 func (cm *ConfigManager) UpdateSchedule(name string, updatedSchedule Schedule) error {
@@ -164,7 +541,24 @@ func (cm *ConfigManager) UpdateSchedule(name string, updatedSchedule Schedule) e
 			return cm.storeConfig()
 		}
 	}
-	return errors.New("schedule not found")
+	return ErrScheduleNotExists
+}
+
+func (cm *ConfigManager) RenameSchedule(oldName string, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("%w: schedule without a name", ErrInvalidConfig)
+	}
+	if _, err := cm.GetSchedule(newName); err == nil {
+		return fmt.Errorf("%w: %s", ErrScheduleExists, newName)
+	}
+
+	for i, schedule := range cm.config.Schedules {
+		if schedule.Name == oldName {
+			cm.config.Schedules[i].Name = newName
+			return cm.storeConfig()
+		}
+	}
+	return ErrScheduleNotExists
 }
 
 func readConfigFromFile(configFile string) (Config, error) {
@@ -182,11 +576,5 @@ func readConfigFromFile(configFile string) (Config, error) {
 		return Config{}, nil
 	}
 
-	var cfg Config
-	err = yaml.Unmarshal(b, &cfg)
-	if err != nil {
-		return Config{}, errors.Join(err, errors.New("could not parse config file"))
-	}
-
-	return cfg, nil
+	return ParseConfig(b)
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRenameDeskPresetRenamesSchedulePresets(t *testing.T) {
+	cm, err := NewConfigManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.config.Desks["desk"] = Desk{Name: "desk", Presets: map[string]Preset{"stand": {Name: "stand", Height: 1.1}}}
+	cm.config.Schedules = []Schedule{
+		{Name: "fixed", DeskName: "desk", PresetName: "Stand"},
+		{Name: "interval", Kind: KindInterval, DeskName: "desk", Interval: &IntervalSchedule{
+			FirstPreset: "sit", FirstDuration: time.Hour, SecondPreset: "stand", SecondDuration: time.Hour,
+		}},
+		{Name: "toggle", DeskName: "desk", Action: &Action{Kind: ActionToggle, SitPreset: "sit", StandPreset: "stand"}},
+		{Name: "other desk", DeskName: "other", PresetName: "stand"},
+	}
+	cm.config.DefaultDesk = "desk"
+	cm.config.Goal = &Goal{AutoMove: true, Preset: "stand"}
+
+	err = cm.RenameDeskPreset("desk", "stand", "up")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewConfigManager(cm.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedules := reloaded.GetSchedules()
+	if got := schedules[0].PresetName; got != "up" {
+		t.Errorf("preset of fixed schedule = %q, want up", got)
+	}
+	if got := schedules[1].Interval; got.FirstPreset != "sit" || got.SecondPreset != "up" {
+		t.Errorf("presets of interval schedule = %q, %q, want sit, up", got.FirstPreset, got.SecondPreset)
+	}
+	if got := schedules[2].Action; got.SitPreset != "sit" || got.StandPreset != "up" {
+		t.Errorf("presets of toggle schedule = %q, %q, want sit, up", got.SitPreset, got.StandPreset)
+	}
+	if got := schedules[3].PresetName; got != "stand" {
+		t.Errorf("preset of schedule of other desk = %q, want stand", got)
+	}
+	if got := reloaded.GetGoal().Preset; got != "up" {
+		t.Errorf("preset of the standing goal = %q, want up", got)
+	}
+}
+
+func TestValidateRejectsAutoPlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"schedule", Config{Schedules: []Schedule{{Name: "stand", Plan: "auto"}}}},
+		{"weekday", Config{Plans: Plans{Days: map[int]string{1: "Auto"}}}},
+		{"active", Config{Plans: Plans{Active: "auto"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if !errors.Is(err, ErrInvalidPlanName) {
+				t.Errorf("Validate() = %v, want %v", err, ErrInvalidPlanName)
+			}
+		})
+	}
+}
+
+func TestLookupPresetMatchesWholeNames(t *testing.T) {
+	desk := Desk{Name: "desk", Presets: map[string]Preset{"standing-high": {Name: "Standing-High", Height: 1.2}}}
+
+	preset, err := LookupPreset(desk, "STANDING-high")
+	if err != nil || preset.Name != "Standing-High" {
+		t.Errorf("LookupPreset(STANDING-high) = %q, %v, want Standing-High", preset.Name, err)
+	}
+
+	_, err = LookupPreset(desk, "stand")
+	var notFound *PresetNotFoundError
+	if !errors.As(err, &notFound) || notFound.Suggestion != "Standing-High" {
+		t.Errorf("LookupPreset(stand) error = %v, want not found suggesting Standing-High", err)
+	}
+}
+
+func TestDeleteDeskPresetRefusesPresetsInUse(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		goal     *Goal
+	}{
+		{"schedule", Schedule{Name: "s", DeskName: "desk", PresetName: "Stand"}, nil},
+		{"interval", Schedule{Name: "s", Kind: KindInterval, DeskName: "desk", Interval: &IntervalSchedule{FirstPreset: "sit", SecondPreset: "stand"}}, nil},
+		{"toggle", Schedule{Name: "s", DeskName: "desk", Action: &Action{Kind: ActionToggle, SitPreset: "sit", StandPreset: "stand"}}, nil},
+		{"goal", Schedule{}, &Goal{AutoMove: true, Preset: "stand"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := NewConfigManager(filepath.Join(t.TempDir(), "config.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cm.config.DefaultDesk = "desk"
+			cm.config.Desks["desk"] = Desk{Name: "desk", Presets: map[string]Preset{
+				"sit":   {Name: "sit", Height: 0.7},
+				"stand": {Name: "stand", Height: 1.1},
+			}}
+			if tt.schedule.Name != "" {
+				cm.config.Schedules = []Schedule{tt.schedule}
+			}
+			cm.config.Goal = tt.goal
+
+			err = cm.DeleteDeskPreset("desk", "stand")
+			if !errors.Is(err, ErrPresetInUse) {
+				t.Fatalf("DeleteDeskPreset error = %v, want %v", err, ErrPresetInUse)
+			}
+			if _, ok := cm.config.Desks["desk"].Presets["stand"]; !ok {
+				t.Errorf("the preset in use was deleted")
+			}
+		})
+	}
+}
+
+func TestStorePrunesExpiredOneOffSchedules(t *testing.T) {
+	cm, err := NewConfigManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cm.config.Desks["desk"] = Desk{Name: "desk", Presets: map[string]Preset{"stand": {Name: "stand", Height: 1.1}}}
+	cm.config.Schedules = []Schedule{
+		{Name: "expired", Kind: KindOnce, DeskName: "desk", PresetName: "stand", At: now.Add(-time.Hour)},
+		{Name: "within grace", Kind: KindOnce, DeskName: "desk", PresetName: "stand", At: now.Add(-time.Hour), GracePeriod: 2 * time.Hour},
+		{Name: "upcoming", Kind: KindOnce, DeskName: "desk", PresetName: "stand", At: now.Add(time.Hour)},
+		{Name: "fixed", DeskName: "desk", PresetName: "stand", Time: "09:00", Days: []int{1}},
+	}
+	shared := cm.config.Schedules
+
+	err = cm.storeConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewConfigManager(cm.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, schedule := range reloaded.GetSchedules() {
+		names = append(names, schedule.Name)
+	}
+	if want := []string{"within grace", "upcoming", "fixed"}; !slices.Equal(names, want) {
+		t.Errorf("stored schedules %q, want %q", names, want)
+	}
+	if shared[0].Name != "expired" {
+		t.Errorf("pruning changed the schedules it was given")
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidDay  = errors.New("invalid day")
+	ErrInvalidTime = errors.New("invalid time")
+)
+
+var dayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+var dayAliases = map[string]int{
+	"sunday":    0,
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+	"sun":       0,
+	"mon":       1,
+	"tue":       2,
+	"tues":      2,
+	"wed":       3,
+	"thu":       4,
+	"thur":      4,
+	"thurs":     4,
+	"fri":       5,
+	"sat":       6,
+}
+
+var dayGroups = map[string][]int{
+	"weekdays": {1, 2, 3, 4, 5},
+	"weekday":  {1, 2, 3, 4, 5},
+	"weekends": {0, 6},
+	"weekend":  {0, 6},
+	"daily":    {0, 1, 2, 3, 4, 5, 6},
+	"everyday": {0, 1, 2, 3, 4, 5, 6},
+	"all":      {0, 1, 2, 3, 4, 5, 6},
+}
+
+// ParseDays parses days of the week given as names, abbreviations or
+// numbers (0=Sunday), ranges like mon-fri, or weekdays, weekends and daily.
+// The result is sorted and without duplicates.
+func ParseDays(dayStrings []string) ([]int, error) {
+	var days []int
+	for _, dayStr := range dayStrings {
+		dayStr = strings.ToLower(strings.TrimSpace(dayStr))
+		if dayStr == "" {
+			continue
+		}
+
+		if group, ok := dayGroups[dayStr]; ok {
+			days = append(days, group...)
+			continue
+		}
+
+		if first, last, ok := strings.Cut(dayStr, "-"); ok {
+			from, err := parseDay(first)
+			if err != nil {
+				return nil, err
+			}
+			to, err := parseDay(last)
+			if err != nil {
+				return nil, err
+			}
+			// Ranges may wrap around the end of the week, like fri-mon.
+			for day := from; ; day = (day + 1) % 7 {
+				days = append(days, day)
+				if day == to {
+					break
+				}
+			}
+			continue
+		}
+
+		day, err := parseDay(dayStr)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	slices.Sort(days)
+	return slices.Compact(days), nil
+}
+
+func parseDay(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if day, err := strconv.Atoi(s); err == nil && day >= 0 && day <= 6 {
+		return day, nil
+	}
+	if day, ok := dayAliases[s]; ok {
+		return day, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrInvalidDay, s)
+}
+
+// FormatDays formats days of the week starting on Monday, collapsing three
+// or more consecutive days into a range like Mon–Fri.
+func FormatDays(days []int) string {
+	var week [7]bool
+	count := 0
+	for _, day := range days {
+		if day >= 0 && day <= 6 && !week[day] {
+			week[day] = true
+			count++
+		}
+	}
+	if count == 7 {
+		return "Every day"
+	}
+
+	var parts []string
+	for i := 0; i < 7; {
+		if !week[(i+1)%7] {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < 7 && week[(j+2)%7] {
+			j++
+		}
+
+		first, last := dayNames[(i+1)%7], dayNames[(j+1)%7]
+		switch j - i {
+		case 0:
+			parts = append(parts, first)
+		case 1:
+			parts = append(parts, first, last)
+		default:
+			parts = append(parts, first+"–"+last)
+		}
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ParseTimeOfDay parses a time like 09:00, 9:00, 9am, 2:30pm, noon or
+// midnight and returns it in HH:MM format.
+func ParseTimeOfDay(s string) (string, error) {
+	value := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	switch value {
+	case "noon":
+		return "12:00", nil
+	case "midnight":
+		return "00:00", nil
+	}
+
+	meridiem := ""
+	if rest, ok := strings.CutSuffix(value, "am"); ok {
+		value, meridiem = rest, "am"
+	} else if rest, ok := strings.CutSuffix(value, "pm"); ok {
+		value, meridiem = rest, "pm"
+	}
+
+	hourStr, minuteStr, hasMinutes := strings.Cut(value, ":")
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil || hour < 0 || len(hourStr) > 2 {
+		return "", fmt.Errorf("%w %q, expected a time like 09:00, 9am or 2:30pm", ErrInvalidTime, s)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteStr)
+		if err != nil || len(minuteStr) != 2 || minute < 0 || minute > 59 {
+			return "", fmt.Errorf("%w %q, expected a time like 09:00, 9am or 2:30pm", ErrInvalidTime, s)
+		}
+	}
+
+	switch meridiem {
+	case "":
+		if hour > 23 {
+			return "", fmt.Errorf("%w %q, the hour must be between 0 and 23", ErrInvalidTime, s)
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return "", fmt.Errorf("%w %q, the hour must be between 1 and 12 with am or pm", ErrInvalidTime, s)
+		}
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	}
+
+	return fmt.Sprintf("%02d:%02d", hour, minute), nil
+}
+
+// ParseTimeRange parses a range of times like 09:00-17:00 or 9am-5pm and
+// returns both ends in HH:MM format.
+func ParseTimeRange(s string) (string, string, error) {
+	first, last, ok := strings.Cut(s, "-")
+	if !ok {
+		return "", "", fmt.Errorf("%w range %q, expected a range like 09:00-17:00 or 9am-5pm", ErrInvalidTime, s)
+	}
+
+	start, err := ParseTimeOfDay(first)
+	if err != nil {
+		return "", "", err
+	}
+	end, err := ParseTimeOfDay(last)
+	if err != nil {
+		return "", "", err
+	}
+	return start, end, nil
+}
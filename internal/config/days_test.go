@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []int
+		err  error
+	}{
+		{in: []string{"monday", "Wed", " fri "}, want: []int{1, 3, 5}},
+		{in: []string{"0", "6"}, want: []int{0, 6}},
+		{in: []string{"tues", "thurs"}, want: []int{2, 4}},
+		{in: []string{"mon-fri"}, want: []int{1, 2, 3, 4, 5}},
+		{in: []string{"fri-mon"}, want: []int{0, 1, 5, 6}},
+		{in: []string{"sat-sat"}, want: []int{6}},
+		{in: []string{"1-3", "wed-thu"}, want: []int{1, 2, 3, 4}},
+		{in: []string{"weekdays"}, want: []int{1, 2, 3, 4, 5}},
+		{in: []string{"weekends", "mon"}, want: []int{0, 1, 6}},
+		{in: []string{"daily", "mon"}, want: []int{0, 1, 2, 3, 4, 5, 6}},
+		{in: []string{"", "sun"}, want: []int{0}},
+		{in: []string{"7"}, err: ErrInvalidDay},
+		{in: []string{"funday"}, err: ErrInvalidDay},
+		{in: []string{"mon-funday"}, err: ErrInvalidDay},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDays(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseDays(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseDays(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDays(t *testing.T) {
+	tests := []struct {
+		days []int
+		want string
+	}{
+		{days: []int{1, 2, 3, 4, 5}, want: "Mon–Fri"},
+		{days: []int{0, 1, 2, 3, 4, 5, 6}, want: "Every day"},
+		{days: []int{6, 0}, want: "Sat,Sun"},
+		{days: []int{5, 6, 0}, want: "Fri–Sun"},
+		{days: []int{1, 3, 5}, want: "Mon,Wed,Fri"},
+		{days: []int{1, 2, 4, 5, 6}, want: "Mon,Tue,Thu–Sat"},
+		{days: []int{0}, want: "Sun"},
+		{days: []int{3, 3, 7, -1}, want: "Wed"},
+		{days: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDays(tt.days); got != tt.want {
+			t.Errorf("FormatDays(%v) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"09:00", "09:00"},
+		{"9:05", "09:05"},
+		{"23:59", "23:59"},
+		{"0:00", "00:00"},
+		{"9am", "09:00"},
+		{"9 AM", "09:00"},
+		{"2:30pm", "14:30"},
+		{"12am", "00:00"},
+		{"12:15am", "00:15"},
+		{"12pm", "12:00"},
+		{"12:45pm", "12:45"},
+		{"11pm", "23:00"},
+		{"noon", "12:00"},
+		{"Midnight", "00:00"},
+		{"17", "17:00"},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeOfDay(tt.in)
+		if err != nil {
+			t.Errorf("ParseTimeOfDay(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeOfDay(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "24:00", "9:60", "9:5", "0am", "13pm", "-1:00", "100", "9xm", "nine"} {
+		if _, err := ParseTimeOfDay(in); !errors.Is(err, ErrInvalidTime) {
+			t.Errorf("ParseTimeOfDay(%q) error = %v, want %v", in, err, ErrInvalidTime)
+		}
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	start, end, err := ParseTimeRange("9am-5:30pm")
+	if err != nil || start != "09:00" || end != "17:30" {
+		t.Errorf("ParseTimeRange = %q, %q, %v, want 09:00, 17:30", start, end, err)
+	}
+	if _, _, err := ParseTimeRange("9am"); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("ParseTimeRange without an end error = %v, want %v", err, ErrInvalidTime)
+	}
+}
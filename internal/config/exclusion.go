@@ -0,0 +1,142 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DateFormat is the format of the dates of exclusions.
+const DateFormat = "2006-01-02"
+
+var (
+	ErrInvalidExclusion   = errors.New("invalid exclusion")
+	ErrExclusionNotExists = errors.New("exclusion not found")
+)
+
+// Exclusion is a day, or a range of days, on which schedules do not run,
+// such as a public holiday or a vacation.
+type Exclusion struct {
+	Start  string `yaml:"start"`            // YYYY-MM-DD
+	End    string `yaml:"end,omitempty"`    // YYYY-MM-DD, inclusive, defaults to Start
+	Reason string `yaml:"reason,omitempty"` // Such as the name of the holiday
+}
+
+// LastDay returns the last excluded day.
+func (e Exclusion) LastDay() string {
+	if e.End == "" {
+		return e.Start
+	}
+	return e.End
+}
+
+// Covers reports whether the day of t, in the location of t, is excluded.
+func (e Exclusion) Covers(t time.Time) bool {
+	day := t.Format(DateFormat)
+	return day >= e.Start && day <= e.LastDay()
+}
+
+func (e Exclusion) Validate() error {
+	start, err := time.Parse(DateFormat, e.Start)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start date %q, expected YYYY-MM-DD", ErrInvalidExclusion, e.Start)
+	}
+	end, err := time.Parse(DateFormat, e.LastDay())
+	if err != nil {
+		return fmt.Errorf("%w: invalid end date %q, expected YYYY-MM-DD", ErrInvalidExclusion, e.End)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: end %s is before start %s", ErrInvalidExclusion, e.End, e.Start)
+	}
+	return nil
+}
+
+func (e Exclusion) String() string {
+	s := e.Start
+	if e.End != "" && e.End != e.Start {
+		s += " to " + e.End
+	}
+	if e.Reason != "" {
+		s += " (" + e.Reason + ")"
+	}
+	return s
+}
+
+// GetExclusions returns the exclusions of the schedule, or the global ones
+// that apply to every schedule when scheduleName is empty.
+func (cm *ConfigManager) GetExclusions(scheduleName string) ([]Exclusion, error) {
+	if scheduleName == "" {
+		return cm.config.Exclusions, nil
+	}
+	schedule, err := cm.GetSchedule(scheduleName)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Exclusions, nil
+}
+
+// AddExclusions adds exclusions to the schedule, or global ones when
+// scheduleName is empty, skipping those that already exist. It returns how
+// many were added.
+func (cm *ConfigManager) AddExclusions(scheduleName string, exclusions ...Exclusion) (int, error) {
+	target, err := cm.exclusionsOf(scheduleName)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, exclusion := range exclusions {
+		err := exclusion.Validate()
+		if err != nil {
+			return 0, err
+		}
+		if exclusion.End == exclusion.Start {
+			exclusion.End = ""
+		}
+		if slices.Contains(*target, exclusion) {
+			continue
+		}
+		*target = append(*target, exclusion)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	slices.SortStableFunc(*target, func(a, b Exclusion) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return added, cm.storeConfig()
+}
+
+// RemoveExclusion removes the exclusions starting on start from the
+// schedule, or the global ones when scheduleName is empty.
+func (cm *ConfigManager) RemoveExclusion(scheduleName string, start string) error {
+	target, err := cm.exclusionsOf(scheduleName)
+	if err != nil {
+		return err
+	}
+
+	remaining := slices.DeleteFunc(*target, func(exclusion Exclusion) bool {
+		return exclusion.Start == start
+	})
+	if len(remaining) == len(*target) {
+		return fmt.Errorf("%w: %s", ErrExclusionNotExists, start)
+	}
+	*target = remaining
+	return cm.storeConfig()
+}
+
+func (cm *ConfigManager) exclusionsOf(scheduleName string) (*[]Exclusion, error) {
+	if scheduleName == "" {
+		return &cm.config.Exclusions, nil
+	}
+	for i := range cm.config.Schedules {
+		if cm.config.Schedules[i].Name == scheduleName {
+			return &cm.config.Schedules[i].Exclusions, nil
+		}
+	}
+	return nil, ErrScheduleNotExists
+}
@@ -0,0 +1,53 @@
+package config
+
+import "time"
+
+// Goal is a daily amount of time to spend standing at a desk during work
+// hours.
+type Goal struct {
+	// DeskName is the desk the goal applies to, the default desk when empty.
+	DeskName string        `yaml:"desk,omitempty"`
+	Standing time.Duration `yaml:"standing"`
+	// Start and End are the work hours in HH:MM.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	Days  []int  `yaml:"days"`
+	// BehindBy is how far behind the expected pace progress may fall before
+	// the daemon reminds you.
+	BehindBy time.Duration `yaml:"behindBy"`
+	// RemindEvery is the minimum time between reminders.
+	RemindEvery time.Duration `yaml:"remindEvery,omitempty"`
+	// AutoMove moves the desk to Preset instead of only reminding.
+	AutoMove bool   `yaml:"autoMove,omitempty"`
+	Preset   string `yaml:"preset,omitempty"`
+}
+
+// DefaultRemindEvery is used for goals without a RemindEvery.
+const DefaultRemindEvery = 30 * time.Minute
+
+// GetGoal returns the standing goal, or nil when none is set.
+func (cm *ConfigManager) GetGoal() *Goal {
+	return cm.config.Goal
+}
+
+// SetGoal replaces the standing goal. A nil goal removes it.
+func (cm *ConfigManager) SetGoal(goal *Goal) error {
+	if goal != nil && goal.AutoMove {
+		deskName := goal.DeskName
+		if deskName == "" {
+			deskName = cm.config.DefaultDesk
+		}
+		desk, err := cm.GetDesk(deskName)
+		if err != nil {
+			return err
+		}
+		preset, err := ResolvePreset(desk, goal.Preset)
+		if err != nil {
+			return err
+		}
+		goal.Preset = preset.Name
+	}
+
+	cm.config.Goal = goal
+	return cm.storeConfig()
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxHistoryEntries is how many previous versions of the config are kept.
+const MaxHistoryEntries = 20
+
+var (
+	ErrNoHistory             = errors.New("no config history to undo")
+	ErrHistoryEntryNotExists = errors.New("config history entry not found")
+)
+
+// HistoryEntry is a previous version of the config, saved right before
+// Command changed it.
+type HistoryEntry struct {
+	ID      int       `yaml:"id"`
+	Time    time.Time `yaml:"time"`
+	Command string    `yaml:"command"`
+}
+
+// SetChangeSource sets the description, usually the command line, recorded
+// in the history for changes made through this manager.
+func (cm *ConfigManager) SetChangeSource(source string) {
+	cm.changeSource = source
+}
+
+// GetHistory returns the saved versions of the config, oldest first.
+func (cm *ConfigManager) GetHistory() ([]HistoryEntry, error) {
+	b, err := os.ReadFile(cm.historyIndexFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not read config history"))
+	}
+
+	var entries []HistoryEntry
+	err = yaml.Unmarshal(b, &entries)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not parse config history"))
+	}
+
+	return entries, nil
+}
+
+// Undo restores the version saved in the history entry with the given id,
+// or the latest one when id is 0. That entry and every later one are removed
+// from the history.
+func (cm *ConfigManager) Undo(id int) (HistoryEntry, error) {
+	entries, err := cm.GetHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if len(entries) == 0 {
+		return HistoryEntry{}, ErrNoHistory
+	}
+
+	index := len(entries) - 1
+	if id != 0 {
+		index = -1
+		for i, entry := range entries {
+			if entry.ID == id {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return HistoryEntry{}, ErrHistoryEntryNotExists
+		}
+	}
+	entry := entries[index]
+
+	b, err := os.ReadFile(cm.historySnapshotFile(entry.ID))
+	if err != nil {
+		return HistoryEntry{}, errors.Join(err, errors.New("could not read config history"))
+	}
+
+	restored, err := ParseConfig(b)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if restored.Desks == nil {
+		restored.Desks = make(map[string]Desk)
+	}
+
+	err = atomicfile.Write(cm.configFile, b, 0644)
+	if err != nil {
+		return HistoryEntry{}, errors.Join(err, errors.New("could not save config file"))
+	}
+	cm.config = &restored
+
+	for _, removed := range entries[index:] {
+		os.Remove(cm.historySnapshotFile(removed.ID))
+	}
+
+	return entry, cm.storeHistory(entries[:index])
+}
+
+// recordHistory saves the config currently on disk as a new history entry
+// before it is replaced by next.
+func (cm *ConfigManager) recordHistory(next []byte) error {
+	current, err := os.ReadFile(cm.configFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, errors.New("could not read config file"))
+	}
+	if len(current) == 0 || bytes.Equal(current, next) {
+		return nil
+	}
+
+	entries, err := cm.GetHistory()
+	if err != nil {
+		return err
+	}
+
+	id := 1
+	if len(entries) > 0 {
+		id = entries[len(entries)-1].ID + 1
+	}
+
+	err = os.MkdirAll(cm.historyDir(), 0755)
+	if err != nil {
+		return errors.Join(err, errors.New("could not create config history directory"))
+	}
+
+	err = atomicfile.Write(cm.historySnapshotFile(id), current, 0644)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save config history"))
+	}
+
+	entries = append(entries, HistoryEntry{
+		ID:      id,
+		Time:    time.Now(),
+		Command: cm.changeSource,
+	})
+	for len(entries) > MaxHistoryEntries {
+		os.Remove(cm.historySnapshotFile(entries[0].ID))
+		entries = entries[1:]
+	}
+
+	return cm.storeHistory(entries)
+}
+
+func (cm *ConfigManager) storeHistory(entries []HistoryEntry) error {
+	b, err := yaml.Marshal(entries)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save config history"))
+	}
+
+	err = atomicfile.Write(cm.historyIndexFile(), b, 0644)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save config history"))
+	}
+
+	return nil
+}
+
+func (cm *ConfigManager) historyDir() string {
+	return cm.configFile + ".history"
+}
+
+func (cm *ConfigManager) historyIndexFile() string {
+	return filepath.Join(cm.historyDir(), "index.yaml")
+}
+
+func (cm *ConfigManager) historySnapshotFile(id int) string {
+	return filepath.Join(cm.historyDir(), fmt.Sprintf("%04d.yaml", id))
+}
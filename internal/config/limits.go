@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidLimits = errors.New("invalid limits")
+
+// Limits guard against schedules that move the desks at the wrong time or
+// too often, such as a misconfigured cron expression. They apply to every
+// automatic move, by schedules and by the standing goal.
+type Limits struct {
+	QuietStart      string `yaml:"quietStart,omitempty"`      // HH:MM, no automatic moves from QuietStart to QuietEnd
+	QuietEnd        string `yaml:"quietEnd,omitempty"`        // HH:MM, before QuietStart when the quiet hours span midnight
+	MaxMovesPerHour int    `yaml:"maxMovesPerHour,omitempty"` // Automatic moves of each desk, unlimited when 0
+}
+
+// InQuietHours reports whether the time of day of t, in the location of t,
+// is within the quiet hours.
+func (l Limits) InQuietHours(t time.Time) bool {
+	if l.QuietStart == "" || l.QuietEnd == "" {
+		return false
+	}
+	clock := t.Format("15:04")
+	if l.QuietStart <= l.QuietEnd {
+		return clock >= l.QuietStart && clock < l.QuietEnd
+	}
+	return clock >= l.QuietStart || clock < l.QuietEnd
+}
+
+func (l Limits) validate() error {
+	if (l.QuietStart == "") != (l.QuietEnd == "") {
+		return fmt.Errorf("%w: quiet hours need a start and an end", ErrInvalidLimits)
+	}
+	for _, clock := range []string{l.QuietStart, l.QuietEnd} {
+		if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+			return fmt.Errorf("%w: invalid quiet hours time %q, expected HH:MM", ErrInvalidLimits, clock)
+		}
+	}
+	if l.QuietStart != "" && l.QuietStart == l.QuietEnd {
+		return fmt.Errorf("%w: quiet hours start and end at %s", ErrInvalidLimits, l.QuietStart)
+	}
+	if l.MaxMovesPerHour < 0 {
+		return fmt.Errorf("%w: the maximum moves per hour must not be negative", ErrInvalidLimits)
+	}
+	return nil
+}
+
+func (cm *ConfigManager) GetLimits() Limits {
+	return cm.config.Limits
+}
+
+func (cm *ConfigManager) SetLimits(limits Limits) error {
+	err := limits.validate()
+	if err != nil {
+		return err
+	}
+	cm.config.Limits = limits
+	return cm.storeConfig()
+}
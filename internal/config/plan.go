@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrPlanNotExists   = errors.New("plan not found")
+	ErrInvalidPlanName = errors.New("invalid plan name")
+)
+
+// AutoPlan stands for following the plans of the weekdays again, so no plan
+// can be named after it.
+const AutoPlan = "auto"
+
+// ValidatePlanName checks that name can be used as a plan. The empty name
+// stands for no plan and is valid.
+func ValidatePlanName(name string) error {
+	if strings.EqualFold(name, AutoPlan) {
+		return fmt.Errorf("%w: %q is reserved for following the plans of the weekdays", ErrInvalidPlanName, name)
+	}
+	return nil
+}
+
+// Plans decides which schedules run. Schedules with a Plan only run while
+// their plan is in use, schedules without one always run.
+type Plans struct {
+	Active string         `yaml:"active,omitempty"` // Plan in use every day, overrides Days
+	Days   map[int]string `yaml:"days,omitempty"`   // Plan in use on each weekday, 0=Sunday, while no plan is Active
+}
+
+// GetPlans returns the names of all plans, those of schedules and those
+// assigned to weekdays, sorted.
+func (cm *ConfigManager) GetPlans() []string {
+	var names []string
+	for _, schedule := range cm.config.Schedules {
+		if schedule.Plan != "" && !slices.Contains(names, schedule.Plan) {
+			names = append(names, schedule.Plan)
+		}
+	}
+	for _, name := range cm.config.Plans.Days {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// GetActivePlan returns the plan set with UsePlan, empty when the plans of
+// the weekdays are followed.
+func (cm *ConfigManager) GetActivePlan() string {
+	return cm.config.Plans.Active
+}
+
+// GetPlanDays returns the plan of every weekday that has one.
+func (cm *ConfigManager) GetPlanDays() map[int]string {
+	return cm.config.Plans.Days
+}
+
+// PlanOn returns the plan in use on the day of t, empty when there is none.
+func (cm *ConfigManager) PlanOn(t time.Time) string {
+	if cm.config.Plans.Active != "" {
+		return cm.config.Plans.Active
+	}
+	return cm.config.Plans.Days[int(t.Weekday())]
+}
+
+// UsePlan makes the plan the one in use every day, or goes back to the plans
+// of the weekdays when name is empty.
+func (cm *ConfigManager) UsePlan(name string) error {
+	if name != "" && !slices.Contains(cm.GetPlans(), name) {
+		return fmt.Errorf("%w: %s", ErrPlanNotExists, name)
+	}
+	cm.config.Plans.Active = name
+	return cm.storeConfig()
+}
+
+// AssignPlan makes the plan the one in use on the weekdays, or removes their
+// plan when name is empty.
+func (cm *ConfigManager) AssignPlan(name string, days []int) error {
+	if name != "" && !slices.Contains(cm.GetPlans(), name) {
+		return fmt.Errorf("%w: %s", ErrPlanNotExists, name)
+	}
+	if cm.config.Plans.Days == nil {
+		cm.config.Plans.Days = make(map[int]string)
+	}
+	for _, day := range days {
+		if name == "" {
+			delete(cm.config.Plans.Days, day)
+			continue
+		}
+		cm.config.Plans.Days[day] = name
+	}
+	return cm.storeConfig()
+}
+
+// planDays returns the weekdays on which the plan is in use.
+func (p Plans) planDays(name string) []int {
+	if p.Active != "" {
+		if p.Active == name {
+			return []int{0, 1, 2, 3, 4, 5, 6}
+		}
+		return nil
+	}
+
+	var days []int
+	for _, day := range slices.Sorted(maps.Keys(p.Days)) {
+		if p.Days[day] == name {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (p Plans) validate() error {
+	if err := ValidatePlanName(p.Active); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	for day, name := range p.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: plan assigned to invalid day %d", ErrInvalidConfig, day)
+		}
+		if err := ValidatePlanName(name); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidPresetSort = errors.New("invalid preset sort, must be one of order or height")
+	ErrPresetAmbiguous   = errors.New("preset name is ambiguous")
+	ErrPresetInUse       = errors.New("preset is in use")
+)
+
+type PresetSort string
+
+const (
+	// SortByOrder sorts presets by their user defined order, presets without
+	// one come last sorted by height.
+	SortByOrder PresetSort = "order"
+	// SortByHeight sorts presets from lowest to highest.
+	SortByHeight PresetSort = "height"
+)
+
+func ParsePresetSort(s string) (PresetSort, error) {
+	switch PresetSort(strings.ToLower(s)) {
+	case SortByOrder:
+		return SortByOrder, nil
+	case SortByHeight:
+		return SortByHeight, nil
+	}
+	return "", ErrInvalidPresetSort
+}
+
+// SortedPresets returns the presets of the desk in a stable order.
+func (d Desk) SortedPresets(by PresetSort) []Preset {
+	presets := make([]Preset, 0, len(d.Presets))
+	for _, preset := range d.Presets {
+		presets = append(presets, preset)
+	}
+
+	sort.Slice(presets, func(i, j int) bool {
+		a, b := presets[i], presets[j]
+		if by == SortByOrder && a.Order != b.Order {
+			if a.Order == 0 || b.Order == 0 {
+				return b.Order == 0
+			}
+			return a.Order < b.Order
+		}
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	return presets
+}
+
+// PresetNotFoundError is returned by ResolvePreset when no preset matches.
+type PresetNotFoundError struct {
+	Name       string
+	DeskName   string
+	Suggestion string
+}
+
+func (e *PresetNotFoundError) Error() string {
+	msg := fmt.Sprintf("preset %q not found on desk %q", e.Name, e.DeskName)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	return msg
+}
+
+func (e *PresetNotFoundError) Unwrap() error {
+	return ErrPresetNotExists
+}
+
+// ResolvePreset finds the preset of the desk referred to by name. Names are
+// matched case-insensitively and a unique prefix is enough; when nothing
+// matches, the error suggests the closest preset name.
+func ResolvePreset(desk Desk, name string) (Preset, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if preset, ok := desk.Presets[key]; ok {
+		return preset, nil
+	}
+
+	var matches []Preset
+	for _, preset := range desk.SortedPresets(SortByHeight) {
+		if strings.ToLower(preset.Name) == key {
+			return preset, nil
+		}
+		if key != "" && strings.HasPrefix(strings.ToLower(preset.Name), key) {
+			matches = append(matches, preset)
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if len(matches) > 1 {
+		var names []string
+		for _, preset := range matches {
+			names = append(names, preset.Name)
+		}
+		return Preset{}, fmt.Errorf("%w: %q matches %s", ErrPresetAmbiguous, name, strings.Join(names, ", "))
+	}
+
+	return Preset{}, presetNotFound(desk, name)
+}
+
+// LookupPreset finds the preset of the desk with the name, ignoring case.
+// Unlike ResolvePreset it does not match prefixes, for names stored in the
+// config and for changes that are hard to undo.
+func LookupPreset(desk Desk, name string) (Preset, error) {
+	if preset, ok := desk.Presets[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return preset, nil
+	}
+	return Preset{}, presetNotFound(desk, name)
+}
+
+// presetNotFound returns the error for a missing preset, suggesting the
+// closest preset name.
+func presetNotFound(desk Desk, name string) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	notFound := &PresetNotFoundError{Name: name, DeskName: desk.Name}
+	for _, preset := range desk.SortedPresets(SortByHeight) {
+		if key != "" && strings.HasPrefix(strings.ToLower(preset.Name), key) {
+			notFound.Suggestion = preset.Name
+			return notFound
+		}
+	}
+
+	bestDistance := len(key)/2 + 1
+	for _, preset := range desk.SortedPresets(SortByHeight) {
+		distance := levenshtein(key, strings.ToLower(preset.Name))
+		if distance <= bestDistance && (notFound.Suggestion == "" || distance < bestDistance) {
+			notFound.Suggestion = preset.Name
+			bestDistance = distance
+		}
+	}
+	return notFound
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInvalidTimezone = errors.New("invalid time zone")
+
+// locations caches the loaded time zones, as loading one reads the zone
+// database.
+var locations sync.Map
+
+// LoadLocation returns the IANA time zone with the given name, such as
+// Europe/Lisbon, or the system's time zone when name is empty.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidTimezone, name, err)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Location returns the time zone the times of the schedule are in.
+func (s Schedule) Location() (*time.Location, error) {
+	return LoadLocation(s.Timezone)
+}
+
+// GetTimezone returns the time zone of schedules without their own, empty
+// for the system's time zone.
+func (cm *ConfigManager) GetTimezone() string {
+	return cm.config.Timezone
+}
+
+// SetTimezone sets the time zone of schedules without their own, the
+// system's time zone when name is empty.
+func (cm *ConfigManager) SetTimezone(name string) error {
+	if _, err := LoadLocation(name); err != nil {
+		return err
+	}
+	cm.config.Timezone = name
+	return cm.storeConfig()
+}
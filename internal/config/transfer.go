@@ -0,0 +1,704 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidConflictStrategy = errors.New("invalid conflict strategy, must be one of skip, overwrite or rename")
+	ErrImportNeedsSingleDesk   = errors.New("importing into a desk requires a file with exactly one desk")
+	ErrImportCommands          = errors.New("imported schedules run commands")
+)
+
+type ConflictStrategy string
+
+const (
+	ConflictSkip      ConflictStrategy = "skip"
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	ConflictRename    ConflictStrategy = "rename"
+)
+
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch ConflictStrategy(strings.ToLower(s)) {
+	case ConflictSkip:
+		return ConflictSkip, nil
+	case ConflictOverwrite:
+		return ConflictOverwrite, nil
+	case ConflictRename:
+		return ConflictRename, nil
+	}
+	return "", ErrInvalidConflictStrategy
+}
+
+type ChangeAction string
+
+const (
+	ChangeAdd       ChangeAction = "add"
+	ChangeOverwrite ChangeAction = "overwrite"
+	ChangeRename    ChangeAction = "rename"
+	ChangeSkip      ChangeAction = "skip"
+)
+
+// Change describes a single modification an import makes, or would make
+// on a dry run, to the current config.
+type Change struct {
+	Action  ChangeAction
+	Kind    string // desk, preset, schedule or a setting such as timezone
+	Name    string
+	NewName string // only set when Action is ChangeRename
+	Detail  string
+	Command string // only set for schedules that run a command
+}
+
+func (c Change) String() string {
+	symbols := map[ChangeAction]string{
+		ChangeAdd:       "+",
+		ChangeOverwrite: "~",
+		ChangeRename:    ">",
+		ChangeSkip:      "=",
+	}
+
+	s := fmt.Sprintf("%s %s %s", symbols[c.Action], c.Kind, c.Name)
+	if c.Action == ChangeRename {
+		s += " -> " + c.NewName
+	}
+	switch {
+	case c.Action == ChangeSkip && c.Detail != "":
+		s += " (skipped, " + c.Detail + ")"
+	case c.Action == ChangeSkip:
+		s += " (skipped, already exists)"
+	case c.Detail != "":
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// ScheduleName returns the name the change stores a schedule under.
+func (c Change) ScheduleName() string {
+	if c.Action == ChangeRename {
+		return c.NewName
+	}
+	return c.Name
+}
+
+// CommandChanges returns the changes that import schedules running a
+// command, which Import refuses without ImportOptions.AllowCommands.
+func CommandChanges(changes []Change) []Change {
+	var commands []Change
+	for _, c := range changes {
+		if c.Command != "" {
+			commands = append(commands, c)
+		}
+	}
+	return commands
+}
+
+type ImportOptions struct {
+	Strategy ConflictStrategy
+	// TargetDesk applies the presets and schedules of the single desk in
+	// the imported file to this local desk, keeping its address.
+	TargetDesk string
+	DryRun     bool
+	// AllowCommands imports schedules that run local commands. Without it
+	// Import refuses them, unless DryRun is set.
+	AllowCommands bool
+	// ValidateSchedule checks a schedule that is about to be imported
+	// against its desk in the merged config, and may replace preset names by
+	// the names they resolve to. It is provided by the caller, as the checks
+	// live in the scheduler package.
+	ValidateSchedule func(schedule *Schedule, desk Desk) error
+}
+
+// ParseConfig decodes a config document such as the output of Export.
+func ParseConfig(b []byte) (Config, error) {
+	var cfg Config
+	err := yaml.Unmarshal(b, &cfg)
+	if err != nil {
+		return Config{}, errors.Join(err, errors.New("could not parse config file"))
+	}
+	return cfg, nil
+}
+
+// Export returns a copy of the desks, presets and schedules in the config.
+// When deskName is set only that desk and its schedules are included.
+func (cm *ConfigManager) Export(deskName string) (Config, error) {
+	cfg := cm.config.clone()
+	if deskName == "" {
+		return cfg, nil
+	}
+
+	desk, ok := cfg.Desks[deskName]
+	if !ok {
+		return Config{}, ErrDeskNotExists
+	}
+
+	exported := Config{
+		Desks: map[string]Desk{deskName: desk},
+	}
+	if cfg.DefaultDesk == deskName {
+		exported.DefaultDesk = deskName
+	}
+	for _, schedule := range cfg.Schedules {
+		if schedule.DeskName == deskName {
+			exported.Schedules = append(exported.Schedules, schedule)
+		}
+	}
+
+	return exported, nil
+}
+
+// Import merges incoming into the current config, resolving conflicts with
+// opts.Strategy, and returns the list of changes. The merged config is
+// validated and stored unless opts.DryRun is set. Settings the config holds
+// once, such as the time zone, keep their current value with the rename
+// strategy; imported schedules then keep the imported time zone.
+func (cm *ConfigManager) Import(incoming Config, opts ImportOptions) ([]Change, error) {
+	incoming = incoming.clone()
+	merged := cm.config.clone()
+
+	if opts.TargetDesk != "" {
+		err := retargetDesk(&incoming, merged, opts.TargetDesk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []Change
+	deskRenames := make(map[string]string)
+	skippedDesks := make(map[string]bool)
+	presetRenames := make(map[string]map[string]string)
+
+	for _, name := range sortedDeskNames(incoming.Desks) {
+		desk := incoming.Desks[name]
+		if desk.Presets == nil {
+			desk.Presets = make(map[string]Preset)
+		}
+
+		existing, ok := merged.Desks[name]
+		if !ok {
+			merged.Desks[name] = desk
+			changes = append(changes, Change{
+				Action: ChangeAdd,
+				Kind:   "desk",
+				Name:   name,
+				Detail: fmt.Sprintf("%d presets", len(desk.Presets)),
+			})
+			continue
+		}
+
+		if desk.Address != "" && desk.Address != existing.Address {
+			switch opts.Strategy {
+			case ConflictSkip:
+				skippedDesks[name] = true
+				changes = append(changes, Change{Action: ChangeSkip, Kind: "desk", Name: name})
+				continue
+			case ConflictOverwrite:
+				existing.Address = desk.Address
+				changes = append(changes, Change{
+					Action: ChangeOverwrite,
+					Kind:   "desk",
+					Name:   name,
+					Detail: "address " + desk.Address,
+				})
+			case ConflictRename:
+				newName := uniqueName(name, func(n string) bool {
+					_, exists := merged.Desks[n]
+					return exists
+				})
+				desk.Name = newName
+				merged.Desks[newName] = desk
+				deskRenames[name] = newName
+				changes = append(changes, Change{Action: ChangeRename, Kind: "desk", Name: name, NewName: newName})
+				continue
+			}
+		}
+
+		if existing.Presets == nil {
+			existing.Presets = make(map[string]Preset)
+		}
+		for _, key := range sortedPresetKeys(desk.Presets) {
+			preset := desk.Presets[key]
+			qualified := name + "/" + preset.Name
+
+			current, ok := existing.Presets[key]
+			if !ok {
+				existing.Presets[key] = preset
+				changes = append(changes, Change{
+					Action: ChangeAdd,
+					Kind:   "preset",
+					Name:   qualified,
+					Detail: fmt.Sprintf("%.2f m", preset.Height),
+				})
+				continue
+			}
+
+			if reflect.DeepEqual(current, preset) {
+				continue
+			}
+
+			switch opts.Strategy {
+			case ConflictSkip:
+				changes = append(changes, Change{Action: ChangeSkip, Kind: "preset", Name: qualified})
+			case ConflictOverwrite:
+				existing.Presets[key] = preset
+				changes = append(changes, Change{
+					Action: ChangeOverwrite,
+					Kind:   "preset",
+					Name:   qualified,
+					Detail: fmt.Sprintf("%.2f m -> %.2f m", current.Height, preset.Height),
+				})
+			case ConflictRename:
+				newName := uniqueName(preset.Name, func(n string) bool {
+					_, exists := existing.Presets[strings.ToLower(n)]
+					return exists
+				})
+				preset.Name = newName
+				existing.Presets[strings.ToLower(newName)] = preset
+				if presetRenames[name] == nil {
+					presetRenames[name] = make(map[string]string)
+				}
+				presetRenames[name][key] = newName
+				changes = append(changes, Change{
+					Action:  ChangeRename,
+					Kind:    "preset",
+					Name:    qualified,
+					NewName: name + "/" + newName,
+					Detail:  fmt.Sprintf("%.2f m", preset.Height),
+				})
+			}
+		}
+		merged.Desks[name] = existing
+	}
+
+	change, ok := mergeSetting(&merged.Timezone, incoming.Timezone, "timezone", describeTimezone, opts.Strategy)
+	if ok {
+		changes = append(changes, change)
+	}
+
+	for _, schedule := range incoming.Schedules {
+		// Its presets may only exist on the skipped desk.
+		if skippedDesks[schedule.DeskName] {
+			changes = append(changes, Change{
+				Action: ChangeSkip,
+				Kind:   "schedule",
+				Name:   schedule.Name,
+				Detail: "desk " + schedule.DeskName + " was skipped",
+			})
+			continue
+		}
+
+		if renamed, ok := presetRenames[schedule.DeskName][strings.ToLower(schedule.PresetName)]; ok {
+			schedule.PresetName = renamed
+		}
+		if schedule.Interval != nil {
+			interval := *schedule.Interval
+			for key, renamed := range presetRenames[schedule.DeskName] {
+				interval.renamePreset(key, renamed)
+			}
+			schedule.Interval = &interval
+		}
+		if schedule.Action != nil {
+			action := *schedule.Action
+			for key, renamed := range presetRenames[schedule.DeskName] {
+				action.renamePreset(key, renamed)
+			}
+			schedule.Action = &action
+		}
+		if renamed, ok := deskRenames[schedule.DeskName]; ok {
+			schedule.DeskName = renamed
+		}
+		// Keep the time zone the schedule ran in when the imported default
+		// time zone was not taken over.
+		if schedule.Timezone == "" && incoming.Timezone != merged.Timezone {
+			schedule.Timezone = incoming.Timezone
+		}
+
+		index := -1
+		for i, s := range merged.Schedules {
+			if s.Name == schedule.Name {
+				index = i
+				break
+			}
+		}
+
+		if index >= 0 && reflect.DeepEqual(merged.Schedules[index], schedule) {
+			continue
+		}
+		if index >= 0 && opts.Strategy == ConflictSkip {
+			changes = append(changes, Change{Action: ChangeSkip, Kind: "schedule", Name: schedule.Name})
+			continue
+		}
+
+		err := validateImportedSchedule(&schedule, merged, opts.ValidateSchedule)
+		if err != nil {
+			return nil, err
+		}
+		var command string
+		if schedule.ActionKind() == ActionCommand {
+			command = schedule.Action.Command
+		}
+
+		if index < 0 {
+			merged.Schedules = append(merged.Schedules, schedule)
+			changes = append(changes, Change{
+				Action:  ChangeAdd,
+				Kind:    "schedule",
+				Name:    schedule.Name,
+				Detail:  describeSchedule(schedule),
+				Command: command,
+			})
+			continue
+		}
+
+		switch opts.Strategy {
+		case ConflictOverwrite:
+			merged.Schedules[index] = schedule
+			changes = append(changes, Change{
+				Action:  ChangeOverwrite,
+				Kind:    "schedule",
+				Name:    schedule.Name,
+				Detail:  describeSchedule(schedule),
+				Command: command,
+			})
+		case ConflictRename:
+			oldName := schedule.Name
+			schedule.Name = uniqueName(oldName, func(n string) bool {
+				for _, s := range merged.Schedules {
+					if s.Name == n {
+						return true
+					}
+				}
+				return false
+			})
+			merged.Schedules = append(merged.Schedules, schedule)
+			changes = append(changes, Change{
+				Action:  ChangeRename,
+				Kind:    "schedule",
+				Name:    oldName,
+				NewName: schedule.Name,
+				Detail:  describeSchedule(schedule),
+				Command: command,
+			})
+		}
+	}
+
+	if merged.DefaultDesk == "" && incoming.DefaultDesk != "" {
+		defaultDesk := incoming.DefaultDesk
+		if renamed, ok := deskRenames[defaultDesk]; ok {
+			defaultDesk = renamed
+		}
+		if _, ok := merged.Desks[defaultDesk]; ok {
+			merged.DefaultDesk = defaultDesk
+		}
+	}
+
+	for _, exclusion := range incoming.Exclusions {
+		if slices.Contains(merged.Exclusions, exclusion) {
+			continue
+		}
+		merged.Exclusions = append(merged.Exclusions, exclusion)
+		changes = append(changes, Change{Action: ChangeAdd, Kind: "exclusion", Name: exclusion.String()})
+	}
+	slices.SortStableFunc(merged.Exclusions, func(a, b Exclusion) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+
+	changes = append(changes, mergePlans(&merged.Plans, incoming.Plans, opts.Strategy)...)
+
+	change, ok = mergeSetting(&merged.Limits, incoming.Limits, "limits", describeLimits, opts.Strategy)
+	if ok {
+		changes = append(changes, change)
+	}
+
+	goal := incoming.Goal
+	if goal != nil {
+		deskName := cmp.Or(goal.DeskName, incoming.DefaultDesk)
+		if renamed, ok := presetRenames[deskName][strings.ToLower(goal.Preset)]; ok {
+			goal.Preset = renamed
+		}
+		if renamed, ok := deskRenames[deskName]; ok {
+			goal.DeskName = renamed
+		}
+	}
+	change, ok = mergeSetting(&merged.Goal, goal, "goal", describeGoal, opts.Strategy)
+	if ok {
+		changes = append(changes, change)
+	}
+	if ok && change.Action != ChangeSkip {
+		err := validateImportedGoal(*merged.Goal, merged)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if commands := CommandChanges(changes); len(commands) > 0 && !opts.AllowCommands && !opts.DryRun {
+		var runs []string
+		for _, c := range commands {
+			runs = append(runs, fmt.Sprintf("schedule %q runs %q", c.ScheduleName(), c.Command))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrImportCommands, strings.Join(runs, ", "))
+	}
+
+	err := merged.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	err = cm.writeConfig(&merged)
+	if err != nil {
+		return nil, err
+	}
+	cm.config = &merged
+
+	return changes, nil
+}
+
+// mergeSetting merges a setting the config holds only once, such as the
+// time zone, and reports the change. As a setting cannot be renamed, the
+// rename strategy keeps the current value like skip.
+func mergeSetting[T any](current *T, incoming T, kind string, describe func(T) string, strategy ConflictStrategy) (Change, bool) {
+	switch {
+	case reflect.ValueOf(&incoming).Elem().IsZero() || reflect.DeepEqual(*current, incoming):
+		return Change{}, false
+	case reflect.ValueOf(current).Elem().IsZero():
+		*current = incoming
+		return Change{Action: ChangeAdd, Kind: kind, Name: describe(incoming)}, true
+	case strategy == ConflictOverwrite:
+		previous := *current
+		*current = incoming
+		return Change{Action: ChangeOverwrite, Kind: kind, Name: describe(incoming), Detail: "was " + describe(previous)}, true
+	}
+	return Change{Action: ChangeSkip, Kind: kind, Name: describe(incoming), Detail: "keeping " + describe(*current)}, true
+}
+
+// mergePlans merges the plans assigned to weekdays and the plan in use.
+func mergePlans(current *Plans, incoming Plans, strategy ConflictStrategy) []Change {
+	var changes []Change
+	days := slices.Sorted(maps.Keys(incoming.Days))
+	for _, day := range days {
+		name := incoming.Days[day]
+		existing, ok := current.Days[day]
+		if ok && existing == name {
+			continue
+		}
+
+		change := Change{Action: ChangeAdd, Kind: "plan", Name: name, Detail: "on " + FormatDays([]int{day})}
+		switch {
+		case ok && strategy != ConflictOverwrite:
+			change.Action = ChangeSkip
+			change.Detail = fmt.Sprintf("keeping %s on %s", existing, FormatDays([]int{day}))
+			changes = append(changes, change)
+			continue
+		case ok:
+			change.Action = ChangeOverwrite
+			change.Detail += ", was " + existing
+		}
+		if current.Days == nil {
+			current.Days = make(map[int]string)
+		}
+		current.Days[day] = name
+		changes = append(changes, change)
+	}
+
+	change, ok := mergeSetting(&current.Active, incoming.Active, "active plan", func(name string) string { return name }, strategy)
+	if ok {
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// validateImportedGoal checks that the preset the goal moves the desk to
+// exists.
+func validateImportedGoal(goal Goal, merged Config) error {
+	if !goal.AutoMove {
+		return nil
+	}
+	deskName := cmp.Or(goal.DeskName, merged.DefaultDesk)
+	desk, ok := merged.Desks[deskName]
+	if !ok {
+		return fmt.Errorf("%w: goal: %w: %s", ErrInvalidConfig, ErrDeskNotExists, deskName)
+	}
+	_, err := LookupPreset(desk, goal.Preset)
+	if err != nil {
+		return fmt.Errorf("%w: goal: %w", ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+func describeTimezone(name string) string {
+	return name
+}
+
+func describeLimits(l Limits) string {
+	var parts []string
+	if l.QuietStart != "" {
+		parts = append(parts, fmt.Sprintf("quiet hours %s-%s", l.QuietStart, l.QuietEnd))
+	}
+	if l.MaxMovesPerHour > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d moves per hour", l.MaxMovesPerHour))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeGoal(g *Goal) string {
+	s := fmt.Sprintf("stand %s from %s to %s on %s", g.Standing, g.Start, g.End, FormatDays(g.Days))
+	if g.AutoMove {
+		s += ", moving to " + g.Preset
+	}
+	return s
+}
+
+// validateImportedSchedule checks that the desk of the schedule exists in the
+// merged config and lets validate check the schedule against it.
+func validateImportedSchedule(schedule *Schedule, merged Config, validate func(*Schedule, Desk) error) error {
+	desk, ok := merged.Desks[schedule.DeskName]
+	if !ok {
+		return fmt.Errorf("%w: schedule %q: %w: %s", ErrInvalidConfig, schedule.Name, ErrDeskNotExists, schedule.DeskName)
+	}
+	if validate == nil {
+		return nil
+	}
+
+	err := validate(schedule, desk)
+	if err != nil {
+		return fmt.Errorf("%w: schedule %q: %w", ErrInvalidConfig, schedule.Name, err)
+	}
+	return nil
+}
+
+// retargetDesk rewrites an incoming config holding a single desk so that its
+// presets and schedules apply to the local desk named target.
+func retargetDesk(incoming *Config, current Config, target string) error {
+	local, ok := current.Desks[target]
+	if !ok {
+		return ErrDeskNotExists
+	}
+	if len(incoming.Desks) != 1 {
+		return ErrImportNeedsSingleDesk
+	}
+
+	var source Desk
+	for _, desk := range incoming.Desks {
+		source = desk
+	}
+
+	incoming.Desks = map[string]Desk{
+		target: {
+			Name:    target,
+			Address: local.Address,
+			Presets: source.Presets,
+		},
+	}
+	incoming.DefaultDesk = ""
+	for i := range incoming.Schedules {
+		if incoming.Schedules[i].DeskName == source.Name {
+			incoming.Schedules[i].DeskName = target
+		}
+	}
+	if goal := incoming.Goal; goal != nil && (goal.DeskName == "" || goal.DeskName == source.Name) {
+		goal.DeskName = target
+	}
+
+	return nil
+}
+
+func (c Config) clone() Config {
+	cloned := Config{
+		Desks:       make(map[string]Desk, len(c.Desks)),
+		DefaultDesk: c.DefaultDesk,
+		Exclusions:  append([]Exclusion(nil), c.Exclusions...),
+		Timezone:    c.Timezone,
+		Limits:      c.Limits,
+		Plans: Plans{
+			Active: c.Plans.Active,
+			Days:   maps.Clone(c.Plans.Days),
+		},
+	}
+
+	for name, desk := range c.Desks {
+		presets := make(map[string]Preset, len(desk.Presets))
+		for key, preset := range desk.Presets {
+			presets[key] = preset
+		}
+		desk.Presets = presets
+		cloned.Desks[name] = desk
+	}
+
+	for _, schedule := range c.Schedules {
+		schedule.Days = append([]int(nil), schedule.Days...)
+		schedule.Exclusions = append([]Exclusion(nil), schedule.Exclusions...)
+		if schedule.Interval != nil {
+			interval := *schedule.Interval
+			schedule.Interval = &interval
+		}
+		if schedule.Action != nil {
+			action := *schedule.Action
+			schedule.Action = &action
+		}
+		cloned.Schedules = append(cloned.Schedules, schedule)
+	}
+
+	if c.Goal != nil {
+		goal := *c.Goal
+		goal.Days = append([]int(nil), goal.Days...)
+		cloned.Goal = &goal
+	}
+
+	return cloned
+}
+
+func uniqueName(name string, exists func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+func sortedDeskNames(desks map[string]Desk) []string {
+	names := make([]string, 0, len(desks))
+	for name := range desks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedPresetKeys(presets map[string]Preset) []string {
+	keys := make([]string, 0, len(presets))
+	for key := range presets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func describeSchedule(s Schedule) string {
+	if s.Kind == KindInterval && s.Interval != nil {
+		return fmt.Sprintf("%s %s / %s %s from %s to %s on %s",
+			s.Interval.FirstPreset, s.Interval.FirstDuration, s.Interval.SecondPreset, s.Interval.SecondDuration,
+			s.Interval.Start, s.Interval.End, s.DeskName)
+	}
+	action := s.DescribeAction(s.PresetName)
+	if s.Cron != "" {
+		return fmt.Sprintf("cron %q on %s: %s", s.Cron, s.DeskName, action)
+	}
+	return fmt.Sprintf("%s on %s: %s", s.Time, s.DeskName, action)
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newImportTarget(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm, err := NewConfigManager(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm.config.Desks["desk"] = Desk{Name: "desk", Address: "aa", Presets: map[string]Preset{"sit": {Name: "sit", Height: 0.7}}}
+	return cm
+}
+
+func TestImportSkipsSchedulesOfSkippedDesk(t *testing.T) {
+	cm := newImportTarget(t)
+	incoming := Config{
+		Desks: map[string]Desk{"desk": {Name: "desk", Address: "bb", Presets: map[string]Preset{"stand": {Name: "stand", Height: 1.1}}}},
+		Schedules: []Schedule{
+			{Name: "stand", DeskName: "desk", PresetName: "stand", Time: "10:00", Enabled: true},
+		},
+	}
+
+	changes, err := cm.Import(incoming, ImportOptions{Strategy: ConflictSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cm.GetSchedules()) != 0 {
+		t.Errorf("imported %d schedules of the skipped desk", len(cm.GetSchedules()))
+	}
+	want := []string{"= desk desk (skipped, already exists)", "= schedule stand (skipped, desk desk was skipped)"}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i, change := range changes {
+		if change.String() != want[i] {
+			t.Errorf("change %d = %q, want %q", i, change, want[i])
+		}
+	}
+}
+
+func TestImportValidatesSchedules(t *testing.T) {
+	errUnknownPreset := errors.New("unknown preset")
+	validate := func(schedule *Schedule, desk Desk) error {
+		if _, ok := desk.Presets[schedule.PresetName]; !ok {
+			return errUnknownPreset
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     error
+	}{
+		{"valid", Schedule{Name: "sit", DeskName: "desk", PresetName: "sit"}, nil},
+		{"unknown preset", Schedule{Name: "stand", DeskName: "desk", PresetName: "stand"}, errUnknownPreset},
+		{"unknown desk", Schedule{Name: "sit", DeskName: "other", PresetName: "sit"}, ErrDeskNotExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newImportTarget(t)
+			_, err := cm.Import(Config{Schedules: []Schedule{tt.schedule}}, ImportOptions{
+				Strategy:         ConflictSkip,
+				ValidateSchedule: validate,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Import error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil && len(cm.GetSchedules()) != 0 {
+				t.Errorf("the invalid schedule was imported")
+			}
+		})
+	}
+}
+
+func TestImportKeepsConfigWhenStoreFails(t *testing.T) {
+	cm := newImportTarget(t)
+	// A directory in place of the config file cannot be replaced.
+	err := os.Remove(cm.Path())
+	if err == nil {
+		err = os.Mkdir(cm.Path(), 0755)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cm.Import(Config{Schedules: []Schedule{{Name: "sit", DeskName: "desk", PresetName: "sit"}}}, ImportOptions{Strategy: ConflictSkip})
+	if err == nil {
+		t.Fatal("Import succeeded, want an error")
+	}
+	if len(cm.GetSchedules()) != 0 {
+		t.Errorf("the config holds the schedules of the failed import")
+	}
+}
+
+func TestImportRefusesCommands(t *testing.T) {
+	incoming := Config{Schedules: []Schedule{
+		{Name: "lights", DeskName: "desk", Action: &Action{Kind: ActionCommand, Command: "hue scene focus"}},
+	}}
+
+	tests := []struct {
+		name string
+		opts ImportOptions
+		want error
+	}{
+		{"refused", ImportOptions{Strategy: ConflictSkip}, ErrImportCommands},
+		{"allowed", ImportOptions{Strategy: ConflictSkip, AllowCommands: true}, nil},
+		{"dry run", ImportOptions{Strategy: ConflictSkip, DryRun: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newImportTarget(t)
+			_, err := cm.Import(incoming, tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Import error = %v, want %v", err, tt.want)
+			}
+			imported := len(cm.GetSchedules()) > 0
+			if want := tt.want == nil && !tt.opts.DryRun; imported != want {
+				t.Errorf("imported = %v, want %v", imported, want)
+			}
+		})
+	}
+}
+
+func TestImportMergesSettings(t *testing.T) {
+	incoming := Config{
+		Desks:       map[string]Desk{"desk": {Name: "desk", Address: "aa", Presets: map[string]Preset{"sit": {Name: "sit", Height: 0.7}}}},
+		DefaultDesk: "desk",
+		Schedules:   []Schedule{{Name: "sit", DeskName: "desk", PresetName: "sit", Plan: "office"}},
+		Timezone:    "Europe/Lisbon",
+		Exclusions:  []Exclusion{{Start: "2026-12-25", Reason: "Christmas"}},
+		Plans:       Plans{Days: map[int]string{1: "office"}},
+		Limits:      Limits{MaxMovesPerHour: 4},
+		Goal:        &Goal{Standing: time.Hour, Start: "09:00", End: "17:00", Days: []int{1}, AutoMove: true, Preset: "sit"},
+	}
+
+	t.Run("added", func(t *testing.T) {
+		cm := newImportTarget(t)
+		_, err := cm.Import(incoming, ImportOptions{Strategy: ConflictSkip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := cm.config
+		if cfg.Timezone != "Europe/Lisbon" || len(cfg.Exclusions) != 1 || cfg.Plans.Days[1] != "office" ||
+			cfg.Limits.MaxMovesPerHour != 4 || cfg.Goal == nil {
+			t.Errorf("settings were not imported: %+v", cfg)
+		}
+		if got := cfg.Schedules[0].Timezone; got != "" {
+			t.Errorf("schedule time zone = %q, want the default", got)
+		}
+	})
+
+	t.Run("kept", func(t *testing.T) {
+		cm := newImportTarget(t)
+		cm.config.Timezone = "Asia/Tokyo"
+		cm.config.Plans.Days = map[int]string{1: "home"}
+		cm.config.Limits.MaxMovesPerHour = 2
+
+		changes, err := cm.Import(incoming, ImportOptions{Strategy: ConflictRename})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg := cm.config
+		if cfg.Timezone != "Asia/Tokyo" || cfg.Plans.Days[1] != "home" || cfg.Limits.MaxMovesPerHour != 2 {
+			t.Errorf("settings were overwritten: %+v", cfg)
+		}
+		if got := cfg.Schedules[0].Timezone; got != "Europe/Lisbon" {
+			t.Errorf("schedule time zone = %q, want Europe/Lisbon", got)
+		}
+
+		skipped := 0
+		for _, change := range changes {
+			if change.Action == ChangeSkip {
+				skipped++
+			}
+		}
+		if skipped != 3 {
+			t.Errorf("changes = %v, want the time zone, plan and limits skipped", changes)
+		}
+	})
+
+	t.Run("overwritten", func(t *testing.T) {
+		cm := newImportTarget(t)
+		cm.config.Timezone = "Asia/Tokyo"
+		cm.config.Plans.Days = map[int]string{1: "home"}
+
+		_, err := cm.Import(incoming, ImportOptions{Strategy: ConflictOverwrite})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cm.config.Timezone != "Europe/Lisbon" || cm.config.Plans.Days[1] != "office" {
+			t.Errorf("settings were not overwritten: %+v", cm.config)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/history"
+)
+
+// Client sends requests to a running daemon.
+type Client struct {
+	path string
+}
+
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Status returns the live state of the desks known to the daemon, the
+// scheduled moves that are counting down and whether they are paused.
+func (c *Client) Status() (Status, error) {
+	var status Status
+	err := c.do(context.Background(), Request{Command: CommandStatus}, func(resp Response) {
+		status.Desks = resp.Desks
+		status.Countdowns = resp.Countdowns
+		status.Paused = resp.Paused
+	})
+	return status, err
+}
+
+// Cancel cancels the counting down moves of the schedule, or all of them
+// when scheduleName is empty.
+func (c *Client) Cancel(scheduleName string) ([]Countdown, error) {
+	return c.countdowns(Request{Command: CommandCancel, Schedule: scheduleName})
+}
+
+// Snooze postpones the counting down moves of the schedule, or all of them
+// when scheduleName is empty, by d.
+func (c *Client) Snooze(scheduleName string, d time.Duration) ([]Countdown, error) {
+	return c.countdowns(Request{Command: CommandSnooze, Schedule: scheduleName, Duration: d})
+}
+
+// Reload makes the daemon read the config file again, so changes take
+// effect right away.
+func (c *Client) Reload() error {
+	return c.do(context.Background(), Request{Command: CommandReload}, func(Response) {})
+}
+
+// Pause stops the scheduled moves until the given time, or until Resume when
+// until is zero.
+func (c *Client) Pause(until time.Time) error {
+	return c.do(context.Background(), Request{Command: CommandPause, Until: until}, func(Response) {})
+}
+
+// Resume ends a pause of the scheduled moves.
+func (c *Client) Resume() error {
+	return c.do(context.Background(), Request{Command: CommandResume}, func(Response) {})
+}
+
+func (c *Client) countdowns(req Request) ([]Countdown, error) {
+	var countdowns []Countdown
+	err := c.do(context.Background(), req, func(resp Response) {
+		countdowns = resp.Countdowns
+	})
+	return countdowns, err
+}
+
+// Move asks the daemon to move the desk to the preset and waits until it is
+// done, sending the heights it reports to updates. Cancelling ctx stops the
+// move.
+func (c *Client) Move(ctx context.Context, deskName string, presetName string, updates chan<- float32) (history.Record, error) {
+	var record history.Record
+	err := c.do(ctx, Request{Command: CommandMove, Desk: deskName, Preset: presetName}, func(resp Response) {
+		if resp.Record != nil {
+			record = *resp.Record
+		}
+		if !resp.Done && updates != nil {
+			select {
+			case updates <- resp.Height:
+			case <-ctx.Done():
+			}
+		}
+	})
+	return record, err
+}
+
+func (c *Client) do(ctx context.Context, req Request, handle func(Response)) error {
+	conn, err := net.Dial("unix", c.path)
+	if err != nil {
+		return ErrDaemonNotRunning
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(b, '\n'))
+	if err != nil {
+		return errors.Join(err, errors.New("could not send request to daemon"))
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var resp Response
+		err = json.Unmarshal(scanner.Bytes(), &resp)
+		if err != nil {
+			return errors.Join(err, errors.New("invalid response from daemon"))
+		}
+
+		handle(resp)
+		if resp.Done {
+			if resp.Error != "" {
+				return errors.New(resp.Error)
+			}
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return errors.New("daemon closed the connection")
+}
@@ -0,0 +1,239 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/history"
+)
+
+var (
+	ErrDaemonNotRunning = errors.New("daemon is not running")
+	ErrDaemonRunning    = errors.New("another daemon is already running")
+	ErrUnknownCommand   = errors.New("unknown command")
+	ErrNoCountdown      = errors.New("no scheduled move is counting down")
+)
+
+const (
+	CommandStatus = "status"
+	CommandMove   = "move"
+	CommandCancel = "cancel"
+	CommandSnooze = "snooze"
+	CommandReload = "reload"
+	CommandPause  = "pause"
+	CommandResume = "resume"
+)
+
+// DeskState is the live state of a desk as seen by the daemon.
+type DeskState struct {
+	Desk      string    `json:"desk"`
+	Connected bool      `json:"connected"`
+	Monitored bool      `json:"monitored"`
+	Height    float32   `json:"height,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Countdown is a scheduled move that was announced and may still be
+// cancelled or snoozed before it runs.
+type Countdown struct {
+	Schedule string    `json:"schedule"`
+	Desk     string    `json:"desk"`
+	Preset   string    `json:"preset"`
+	Action   string    `json:"action"`
+	At       time.Time `json:"at"`
+	Snoozed  bool      `json:"snoozed,omitempty"`
+}
+
+// Pause is a pause of the scheduled moves, which lasts until resumed when
+// Until is zero.
+type Pause struct {
+	Until time.Time `json:"until,omitzero"`
+}
+
+// Status is the live state of the daemon.
+type Status struct {
+	Desks      []DeskState `json:"desks"`
+	Countdowns []Countdown `json:"countdowns"`
+	Paused     *Pause      `json:"paused,omitempty"`
+}
+
+// Request is sent by a client, one per connection.
+type Request struct {
+	Command  string        `json:"command"`
+	Desk     string        `json:"desk,omitempty"`
+	Preset   string        `json:"preset,omitempty"`
+	Schedule string        `json:"schedule,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Until    time.Time     `json:"until,omitzero"`
+}
+
+// Response is sent by the daemon. Commands that take a while send any number
+// of progress responses followed by one with Done set.
+type Response struct {
+	Done       bool            `json:"done,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Desks      []DeskState     `json:"desks,omitempty"`
+	Countdowns []Countdown     `json:"countdowns,omitempty"`
+	Paused     *Pause          `json:"paused,omitempty"`
+	Height     float32         `json:"height,omitempty"`
+	Record     *history.Record `json:"record,omitempty"`
+}
+
+// Handler carries out the requests received by a Server.
+type Handler interface {
+	Status() Status
+	// Move moves the desk to the preset, sending heights to updates while
+	// it moves. ctx is cancelled when the client goes away.
+	Move(ctx context.Context, deskName string, presetName string, updates chan<- float32) (history.Record, error)
+	// Cancel cancels the counting down moves of the schedule, or all of them
+	// when scheduleName is empty, and returns the cancelled ones.
+	Cancel(scheduleName string) ([]Countdown, error)
+	// Snooze postpones the counting down moves of the schedule, or all of
+	// them when scheduleName is empty, by d and returns the snoozed ones.
+	Snooze(scheduleName string, d time.Duration) ([]Countdown, error)
+	// Reload reads the config file again.
+	Reload() error
+	// Pause stops the scheduled moves until the given time, or until Resume
+	// when until is zero.
+	Pause(until time.Time) error
+	// Resume ends a pause of the scheduled moves.
+	Resume() error
+}
+
+// SocketFile returns the path of the daemon's control socket for configFile.
+func SocketFile(configFile string) string {
+	return configFile + ".sock"
+}
+
+// Server accepts requests on a unix socket.
+type Server struct {
+	listener net.Listener
+	handler  Handler
+}
+
+// Listen creates the control socket, replacing a stale one left behind by a
+// daemon that did not shut down cleanly.
+func Listen(path string, handler Handler) (*Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, ErrDaemonRunning
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not create control socket"))
+	}
+
+	return &Server{
+		listener: listener,
+		handler:  handler,
+	}, nil
+}
+
+// Serve handles connections until ctx is done.
+func (s *Server) Serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error accepting control connection: %v", err)
+			}
+			return
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(conn)
+	var req Request
+	err = json.Unmarshal(line, &req)
+	if err != nil {
+		enc.Encode(Response{Done: true, Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	// The client closing its end cancels what it asked for.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		reader.ReadByte()
+		cancel()
+	}()
+
+	switch req.Command {
+	case CommandStatus:
+		status := s.handler.Status()
+		enc.Encode(Response{Done: true, Desks: status.Desks, Countdowns: status.Countdowns, Paused: status.Paused})
+	case CommandMove:
+		updates := make(chan float32)
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			for height := range updates {
+				enc.Encode(Response{Height: height})
+			}
+		}()
+
+		record, err := s.handler.Move(ctx, req.Desk, req.Preset, updates)
+		close(updates)
+		<-forwarded
+		enc.Encode(doneResponse(&record, err))
+	case CommandCancel:
+		countdowns, err := s.handler.Cancel(req.Schedule)
+		enc.Encode(countdownResponse(countdowns, err))
+	case CommandSnooze:
+		countdowns, err := s.handler.Snooze(req.Schedule, req.Duration)
+		enc.Encode(countdownResponse(countdowns, err))
+	case CommandReload:
+		enc.Encode(doneResponse(nil, s.handler.Reload()))
+	case CommandPause:
+		enc.Encode(doneResponse(nil, s.handler.Pause(req.Until)))
+	case CommandResume:
+		enc.Encode(doneResponse(nil, s.handler.Resume()))
+	default:
+		enc.Encode(Response{Done: true, Error: fmt.Sprintf("%v: %s", ErrUnknownCommand, req.Command)})
+	}
+}
+
+func doneResponse(record *history.Record, err error) Response {
+	resp := Response{Done: true, Record: record}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+func countdownResponse(countdowns []Countdown, err error) Response {
+	resp := Response{Done: true, Countdowns: countdowns}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
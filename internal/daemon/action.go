@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+)
+
+// commandTimeout bounds a command run by a schedule.
+const commandTimeout = 5 * time.Minute
+
+// runAction runs a due event of a schedule whose action does not move the
+// desk.
+func (d *Daemon) runAction(event scheduler.Event) {
+	schedule := event.Schedule
+	log.Printf("Executing schedule %s: %s", schedule.Name, event.Describe())
+
+	switch schedule.ActionKind() {
+	case config.ActionNotify:
+		err := d.notifier.SendNotification("Reminder", schedule.Action.NotifyMessage())
+		if err != nil {
+			log.Printf("Error sending notification of schedule %s: %v", schedule.Name, err)
+		}
+	case config.ActionCommand:
+		go d.runCommand(schedule)
+	}
+}
+
+// runCommand runs the command of the schedule and notifies when it fails.
+func (d *Daemon) runCommand(schedule config.Schedule) {
+	ctx, cancel := context.WithTimeout(d.ctx, commandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "sh", "-c", schedule.Action.Command).CombinedOutput()
+	if output = bytes.TrimSpace(output); len(output) > 0 {
+		log.Printf("Output of schedule %s: %s", schedule.Name, output)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", commandTimeout)
+	}
+	if err == nil {
+		return
+	}
+
+	log.Printf("Error running command of schedule %s: %v", schedule.Name, err)
+	message := fmt.Sprintf("Schedule '%s' could not run %q: %v", schedule.Name, schedule.Action.Command, err)
+	err = d.notifier.SendNotification("Scheduled Command Failed", message)
+	if err != nil {
+		log.Printf("Error sending failure notification of schedule %s: %v", schedule.Name, err)
+	}
+}
@@ -0,0 +1,402 @@
+package daemon
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/idasen"
+)
+
+var ErrDeskChanged = errors.New("desk changed in the config")
+
+const (
+	// settleDelay is how long the height must stay unchanged before a
+	// monitored desk's new height is recorded as a sample.
+	settleDelay = 2 * time.Second
+
+	minReconnectDelay = 5 * time.Second
+	maxReconnectDelay = 2 * time.Minute
+
+	// maxQueuedMoves bounds the moves waiting for a desk's worker.
+	maxQueuedMoves = 16
+)
+
+// deskConnection is the daemon's link to a single desk. When monitored it is
+// kept open and reconnected after errors, and every height change reported
+// by the desk is observed. Otherwise it is opened for each use and closed
+// again, leaving the desk free for other apps. Its worker runs the moves of
+// the desk, so different desks move in parallel.
+type deskConnection struct {
+	d       *Daemon
+	name    string
+	monitor bool
+
+	// connectMu serializes connecting, mu guards the fields below.
+	connectMu  sync.Mutex
+	mu         sync.Mutex
+	controller *idasen.Controller
+	// address is the address the controller is connected to, the desk's
+	// address in the config may have changed since.
+	address string
+	state   control.DeskState
+	users   int
+	// stale is set when the desk changed in the config while the
+	// connection was in use. It is dropped once the last user releases it.
+	stale bool
+	lost  chan struct{}
+	// settle is closed to cancel the pending sample of an observed height.
+	settle chan struct{}
+	// latest is the most recently submitted move.
+	latest *moveJob
+
+	// submitMu serializes submitting moves with removing the connection,
+	// so no move is queued after the worker stopped.
+	submitMu sync.Mutex
+	removed  bool
+	queue    chan *moveJob
+	// stopped is closed when the desk was removed from the config, which
+	// stops the worker and the reconnecting.
+	stopped chan struct{}
+}
+
+func newDeskConnection(d *Daemon, name string, monitor bool) *deskConnection {
+	return &deskConnection{
+		d:       d,
+		name:    name,
+		monitor: monitor,
+		state: control.DeskState{
+			Desk:      name,
+			Monitored: monitor,
+		},
+		lost:    make(chan struct{}, 1),
+		queue:   make(chan *moveJob, maxQueuedMoves),
+		stopped: make(chan struct{}),
+	}
+}
+
+// acquire returns a connected controller. Every successful acquire must be
+// followed by a release.
+func (c *deskConnection) acquire() (*idasen.Controller, error) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
+	err := c.ensureConnected()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users++
+	return c.controller, nil
+}
+
+// release gives back a controller returned by acquire. A non-nil err means
+// the connection failed and is dropped.
+func (c *deskConnection) release(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.users--
+	if err != nil {
+		c.dropLocked(err)
+		return
+	}
+	if c.users > 0 {
+		return
+	}
+	if c.stale {
+		c.dropLocked(ErrDeskChanged)
+	} else if !c.monitor {
+		c.closeLocked()
+	}
+}
+
+// ensureConnected opens the connection unless it is open. The caller must
+// hold connectMu.
+func (c *deskConnection) ensureConnected() error {
+	c.mu.Lock()
+	connected := c.controller != nil
+	c.mu.Unlock()
+	if connected {
+		return nil
+	}
+
+	// The desk is looked up on every connect, as its address may have
+	// changed since the connection was created.
+	desk, err := c.d.config().GetDesk(c.name)
+	if err != nil {
+		c.mu.Lock()
+		c.state.Error = err.Error()
+		c.mu.Unlock()
+		return err
+	}
+
+	controller, err := idasen.NewController(desk.Address)
+	if err != nil {
+		c.mu.Lock()
+		c.state.Error = err.Error()
+		c.mu.Unlock()
+		return err
+	}
+
+	if c.monitor {
+		err = controller.WatchHeight(c.observe)
+		if err != nil {
+			log.Printf("Error watching height of desk %s, falling back to polling: %v", c.name, err)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.controller = controller
+	c.address = desk.Address
+	c.state.Connected = true
+	c.state.Error = ""
+	return nil
+}
+
+func (c *deskConnection) dropLocked(err error) {
+	log.Printf("Lost connection to desk %s: %v", c.name, err)
+	c.closeLocked()
+	c.state.Error = err.Error()
+
+	select {
+	case c.lost <- struct{}{}:
+	default:
+	}
+}
+
+func (c *deskConnection) closeLocked() {
+	c.stale = false
+	if c.controller == nil {
+		return
+	}
+	c.controller.Close()
+	c.controller = nil
+	c.state.Connected = false
+}
+
+func (c *deskConnection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+// observe updates the live height from a notification and records it once
+// the desk stops moving.
+func (c *deskConnection) observe(height float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state.Height = height
+	c.state.UpdatedAt = c.d.clock.Now()
+
+	if c.settle != nil {
+		close(c.settle)
+	}
+	cancel := make(chan struct{})
+	c.settle = cancel
+
+	timer := c.d.clock.NewTimer(settleDelay)
+	go func() {
+		select {
+		case <-timer.C():
+		case <-cancel:
+			timer.Stop()
+			return
+		case <-c.d.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		c.mu.Lock()
+		settled := c.settle == cancel
+		if settled {
+			c.settle = nil
+		}
+		c.mu.Unlock()
+		if settled {
+			c.d.recordSample(c.name, height)
+		}
+	}()
+}
+
+// sample reads the height of the desk, records it and returns it.
+func (c *deskConnection) sample() (float32, bool) {
+	controller, err := c.acquire()
+	if err != nil {
+		log.Printf("Error connecting to desk %s for a height sample: %v", c.name, err)
+		return 0, false
+	}
+
+	height, err := controller.GetCurrentHeight()
+	c.release(err)
+	if err != nil {
+		log.Printf("Error reading height of desk %s: %v", c.name, err)
+		return 0, false
+	}
+
+	c.setHeight(height)
+	c.d.recordSample(c.name, height)
+	return height, true
+}
+
+// setHeight remembers the height of the desk read outside of monitoring.
+func (c *deskConnection) setHeight(height float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state.Height = height
+	c.state.UpdatedAt = c.d.clock.Now()
+}
+
+// keepConnected connects to a monitored desk and reconnects with a growing
+// delay whenever the connection is lost, until the daemon stops.
+func (c *deskConnection) keepConnected() {
+	delay := minReconnectDelay
+	for {
+		c.connectMu.Lock()
+		err := c.ensureConnected()
+		c.connectMu.Unlock()
+
+		if err == nil {
+			delay = minReconnectDelay
+			select {
+			case <-c.d.ctx.Done():
+				c.close()
+				return
+			case <-c.stopped:
+				return
+			case <-c.lost:
+			}
+			continue
+		}
+
+		log.Printf("Error connecting to desk %s, retrying in %s: %v", c.name, delay, err)
+		wait := c.d.clock.NewTimer(delay)
+		select {
+		case <-c.d.ctx.Done():
+			wait.Stop()
+			return
+		case <-c.stopped:
+			wait.Stop()
+			return
+		case <-wait.C():
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (c *deskConnection) status() control.DeskState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// height returns the last known height of the desk.
+func (c *deskConnection) height() (float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.Height, !c.state.UpdatedAt.IsZero()
+}
+
+// lastHeight returns the last known height of the desk without connecting
+// to it.
+func (d *Daemon) lastHeight(deskName string) (float32, bool) {
+	conn, ok := d.existingConnection(deskName)
+	if !ok {
+		return 0, false
+	}
+	return conn.height()
+}
+
+// existingConnection returns the connection to the desk if it was created.
+func (d *Daemon) existingConnection(deskName string) (*deskConnection, bool) {
+	d.connectionsMu.Lock()
+	defer d.connectionsMu.Unlock()
+	conn, ok := d.connections[deskName]
+	return conn, ok
+}
+
+// connection returns the connection to the desk, creating it on first use.
+func (d *Daemon) connection(desk config.Desk) *deskConnection {
+	d.connectionsMu.Lock()
+	defer d.connectionsMu.Unlock()
+
+	conn, ok := d.connections[desk.Name]
+	if !ok {
+		conn = newDeskConnection(d, desk.Name, d.monitor)
+		d.connections[desk.Name] = conn
+		go conn.work()
+		if d.monitor {
+			go conn.keepConnected()
+		}
+	}
+	return conn
+}
+
+// dropChangedConnections removes the connections to desks that were
+// removed, and closes the open connections to desks whose address changed so
+// they are opened again with the current config. Connections in use are
+// closed once they are released.
+func (d *Daemon) dropChangedConnections() {
+	d.connectionsMu.Lock()
+	defer d.connectionsMu.Unlock()
+
+	for name, conn := range d.connections {
+		desk, err := d.config().GetDesk(name)
+		if err != nil {
+			delete(d.connections, name)
+			conn.remove()
+			log.Printf("Removed connection to desk %s", name)
+			continue
+		}
+
+		conn.mu.Lock()
+		if conn.controller != nil && desk.Address != conn.address {
+			conn.dropChangedLocked()
+		}
+		conn.mu.Unlock()
+	}
+}
+
+// dropChangedLocked drops the connection after the desk changed, or marks it
+// stale while it is in use.
+func (c *deskConnection) dropChangedLocked() {
+	if c.users > 0 {
+		c.stale = true
+		return
+	}
+	c.dropLocked(ErrDeskChanged)
+}
+
+// remove stops the worker and the reconnecting of a connection to a desk
+// that was removed from the config, and closes it.
+func (c *deskConnection) remove() {
+	c.submitMu.Lock()
+	c.removed = true
+	close(c.stopped)
+	c.submitMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.controller != nil {
+		c.dropChangedLocked()
+	}
+}
+
+func (d *Daemon) recordSample(deskName string, height float32) {
+	err := d.samples.Append(history.Sample{
+		Time:   d.clock.Now(),
+		Desk:   deskName,
+		Height: height,
+	})
+	if err != nil {
+		log.Printf("Error recording height of desk %s: %v", deskName, err)
+	}
+}
@@ -0,0 +1,299 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/notification"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+)
+
+// notificationSnooze is how long the snooze button of a countdown
+// notification postpones the move.
+const notificationSnooze = 10 * time.Minute
+
+var countdownActions = []notification.Action{
+	{Key: control.CommandCancel, Label: "Cancel"},
+	{Key: control.CommandSnooze, Label: "Snooze 10m"},
+}
+
+// countdown is an announced scheduled move. It runs at runAt unless it is
+// cancelled, and snoozing it moves runAt.
+type countdown struct {
+	event     scheduler.Event
+	runAt     time.Time
+	cancelled bool
+	// snoozes counts the snoozes, so the timers of earlier ones give up.
+	snoozes int
+	// ran is set when a move snoozed to before its original time ran.
+	ran     bool
+	dismiss func()
+}
+
+func (c *countdown) state() control.Countdown {
+	return control.Countdown{
+		Schedule: c.event.Schedule.Name,
+		Desk:     c.event.Schedule.DeskName,
+		Preset:   c.event.PresetName,
+		Action:   c.event.Describe(),
+		At:       c.runAt,
+		Snoozed:  c.snoozes > 0,
+	}
+}
+
+// startCountdown announces the move of event, which runs at its time unless
+// somebody objects in the meantime.
+func (d *Daemon) startCountdown(event scheduler.Event) {
+	if d.paused() || d.quietHours() || !event.Schedule.MovesDesk() {
+		return
+	}
+
+	c := &countdown{
+		event:   event,
+		runAt:   event.At,
+		dismiss: func() {},
+	}
+
+	d.countdownsMu.Lock()
+	if previous, ok := d.countdowns[event.Schedule.Name]; ok {
+		previous.dismiss()
+	}
+	d.countdowns[event.Schedule.Name] = c
+	d.countdownsMu.Unlock()
+
+	d.announce(c, 0)
+}
+
+// announce sends the notification of a countdown with buttons to cancel or
+// snooze the move.
+func (d *Daemon) announce(c *countdown, snoozes int) {
+	schedule := c.event.Schedule
+	message := fmt.Sprintf("Your desk will %s at %s", c.event.Describe(), c.runAt.Format("15:04"))
+	title := "Desk Movement Scheduled"
+
+	dismiss, err := d.notifier.SendActionNotification(title, message, countdownActions, func(key string) {
+		var err error
+		switch key {
+		case control.CommandCancel:
+			_, err = d.Cancel(schedule.Name)
+		case control.CommandSnooze:
+			_, err = d.Snooze(schedule.Name, notificationSnooze)
+		}
+		if err != nil {
+			log.Printf("Error handling notification action %s of schedule %s: %v", key, schedule.Name, err)
+		}
+	})
+	if err != nil {
+		log.Printf("Error sending notification for schedule %s: %v", schedule.Name, err)
+	}
+
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+	if d.current(c, snoozes) {
+		c.dismiss = dismiss
+		return
+	}
+	dismiss()
+}
+
+// current reports whether the countdown is still pending as it was after the
+// given number of snoozes. The caller must hold countdownsMu.
+func (d *Daemon) current(c *countdown, snoozes int) bool {
+	return d.countdowns[c.event.Schedule.Name] == c && !c.cancelled && c.snoozes == snoozes
+}
+
+// endCountdown is called when the scheduler runs event and reports whether
+// the move should go ahead, which it does unless its countdown was
+// cancelled or snoozed.
+func (d *Daemon) endCountdown(event scheduler.Event) bool {
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+
+	c, ok := d.countdowns[event.Schedule.Name]
+	if !ok || !c.event.At.Equal(event.At) {
+		return true
+	}
+	if c.snoozes > 0 && !c.cancelled && !c.ran {
+		return false
+	}
+
+	delete(d.countdowns, event.Schedule.Name)
+	c.dismiss()
+	return !c.cancelled && !c.ran
+}
+
+// dropChangedCountdowns drops the countdowns of schedules that were removed,
+// disabled or changed since they were announced, as they would no longer
+// run as announced.
+func (d *Daemon) dropChangedCountdowns() {
+	schedules := make(map[string]config.Schedule)
+	for _, schedule := range d.schedules() {
+		schedules[schedule.Name] = schedule
+	}
+
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+	for name, c := range d.countdowns {
+		schedule, ok := schedules[name]
+		if ok && schedule.Enabled && reflect.DeepEqual(schedule, c.event.Schedule) {
+			continue
+		}
+		log.Printf("Dropping the countdown of schedule %s, which was changed", name)
+		c.dismiss()
+		delete(d.countdowns, name)
+	}
+}
+
+// Countdowns returns the scheduled moves that are counting down.
+func (d *Daemon) Countdowns() []control.Countdown {
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+	return d.pendingCountdowns("")
+}
+
+// pendingCountdowns returns the countdowns of the schedule, or all of them
+// when scheduleName is empty, ordered by when they run. The caller must hold
+// countdownsMu.
+func (d *Daemon) pendingCountdowns(scheduleName string) []control.Countdown {
+	now := d.clock.Now()
+	countdowns := []control.Countdown{}
+	for name, c := range d.countdowns {
+		if c.cancelled || c.ran || !c.runAt.After(now) || (scheduleName != "" && name != scheduleName) {
+			continue
+		}
+		countdowns = append(countdowns, c.state())
+	}
+	sort.Slice(countdowns, func(i, j int) bool {
+		return countdowns[i].At.Before(countdowns[j].At)
+	})
+	return countdowns
+}
+
+func noCountdown(scheduleName string) error {
+	if scheduleName != "" {
+		return fmt.Errorf("%w for schedule %s", control.ErrNoCountdown, scheduleName)
+	}
+	return control.ErrNoCountdown
+}
+
+// Cancel cancels the counting down moves of the schedule, or all of them
+// when scheduleName is empty.
+func (d *Daemon) Cancel(scheduleName string) ([]control.Countdown, error) {
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+
+	countdowns := d.pendingCountdowns(scheduleName)
+	if len(countdowns) == 0 {
+		return nil, noCountdown(scheduleName)
+	}
+
+	for _, pending := range countdowns {
+		c := d.countdowns[pending.Schedule]
+		c.cancelled = true
+		c.dismiss()
+
+		log.Printf("Move of schedule %s at %s was cancelled during its countdown", pending.Schedule, pending.At.Format("15:04"))
+		d.recordRun(history.Record{
+			Time:     d.clock.Now(),
+			Desk:     pending.Desk,
+			Trigger:  history.TriggerSchedule,
+			Schedule: pending.Schedule,
+			Preset:   pending.Preset,
+			Outcome:  history.OutcomeCancelled,
+			Reason:   "cancelled during the countdown",
+		})
+	}
+	return countdowns, nil
+}
+
+// Snooze postpones the counting down moves of the schedule, or all of them
+// when scheduleName is empty, to duration from now. They are announced
+// again ahead of the new time.
+func (d *Daemon) Snooze(scheduleName string, duration time.Duration) ([]control.Countdown, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid snooze duration %s", duration)
+	}
+
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+
+	countdowns := d.pendingCountdowns(scheduleName)
+	if len(countdowns) == 0 {
+		return nil, noCountdown(scheduleName)
+	}
+
+	runAt := d.clock.Now().Add(duration)
+	for i, pending := range countdowns {
+		c := d.countdowns[pending.Schedule]
+		c.runAt = runAt
+		c.snoozes++
+		c.dismiss()
+		c.dismiss = func() {}
+
+		log.Printf("Move of schedule %s was snoozed until %s", pending.Schedule, runAt.Format("15:04"))
+		go d.runSnoozed(c, c.snoozes)
+		countdowns[i] = c.state()
+	}
+	return countdowns, nil
+}
+
+// runSnoozed announces a snoozed move again ahead of its new time and then
+// runs it, unless it was cancelled or snoozed again meanwhile.
+func (d *Daemon) runSnoozed(c *countdown, snoozes int) {
+	d.countdownsMu.Lock()
+	runAt := c.runAt
+	d.countdownsMu.Unlock()
+
+	notifyAt := runAt.Add(-c.event.Schedule.CountdownDuration())
+	if !d.sleepUntil(notifyAt) {
+		return
+	}
+
+	d.countdownsMu.Lock()
+	pending := d.current(c, snoozes)
+	d.countdownsMu.Unlock()
+	if !pending {
+		return
+	}
+	d.announce(c, snoozes)
+
+	if !d.sleepUntil(runAt) {
+		return
+	}
+
+	d.countdownsMu.Lock()
+	pending = d.current(c, snoozes)
+	if pending {
+		c.dismiss()
+		// The scheduler still has to reach the original time, which must
+		// not run the move again.
+		if c.event.At.After(d.clock.Now()) {
+			c.ran = true
+		} else {
+			delete(d.countdowns, c.event.Schedule.Name)
+		}
+	}
+	d.countdownsMu.Unlock()
+
+	if pending {
+		d.runSchedule(c.event)
+	}
+}
+
+// sleepUntil waits until t and reports false when the daemon stopped first.
+func (d *Daemon) sleepUntil(t time.Time) bool {
+	timer := d.clock.NewTimer(max(t.Sub(d.clock.Now()), 0))
+	select {
+	case <-d.ctx.Done():
+		timer.Stop()
+		return false
+	case <-timer.C():
+		return true
+	}
+}
@@ -6,29 +6,121 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/samueltorres/idasenctl/internal/config"
-	"github.com/samueltorres/idasenctl/internal/idasen"
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/history"
 	"github.com/samueltorres/idasenctl/internal/notification"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+	"github.com/samueltorres/idasenctl/internal/state"
 )
 
+// SampleInterval is how often the daemon records the height of every
+// monitored desk for statistics.
+const SampleInterval = 5 * time.Minute
+
+type Options struct {
+	// Monitor keeps a connection to every desk open to observe all height
+	// changes, including those made with the desk's buttons.
+	Monitor bool
+	// Clock drives the scheduler, the real clock when nil.
+	Clock scheduler.Clock
+}
+
 type Daemon struct {
-	configManager *config.ConfigManager
-	notifier      *notification.Notifier
-	ctx           context.Context
-	cancel        context.CancelFunc
+	// configManager is replaced when the config is reloaded. configMu
+	// serializes replacing it.
+	configManager atomic.Pointer[config.ConfigManager]
+	configMu      sync.Mutex
+	stateStore    *state.Store
+	runHistory    *history.Store
+	samples       *history.SampleStore
+	monitor       bool
+	connections   map[string]*deskConnection
+	connectionsMu sync.Mutex
+	lastReminder  time.Time
+	countdowns    map[string]*countdown
+	countdownsMu  sync.Mutex
+	// autoMoves holds the times of the automatic moves of each desk in the
+	// last hour, for the maximum moves per hour.
+	autoMoves   map[string][]time.Time
+	autoMovesMu sync.Mutex
+	// pauseEnd is closed to stop waiting for the end of a timed pause.
+	pauseEnd   chan struct{}
+	pauseEndMu sync.Mutex
+	notifier   *notification.Notifier
+	clock      scheduler.Clock
+	scheduler  *scheduler.Scheduler
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
-func NewDaemon(configManager *config.ConfigManager) *Daemon {
+func NewDaemon(configManager *config.ConfigManager, opts Options) (*Daemon, error) {
+	clock := opts.Clock
+	if clock == nil {
+		clock = scheduler.NewRealClock()
+	}
+
+	stateStore, err := state.NewStore(state.StateFile(configManager.Path()))
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Daemon{
-		configManager: configManager,
-		notifier:      notification.NewNotifier(),
-		ctx:           ctx,
-		cancel:        cancel,
+	d := &Daemon{
+		stateStore:  stateStore,
+		runHistory:  history.NewStore(history.HistoryFile(configManager.Path())),
+		samples:     history.NewSampleStore(history.SamplesFile(configManager.Path())),
+		monitor:     opts.Monitor,
+		connections: make(map[string]*deskConnection),
+		countdowns:  make(map[string]*countdown),
+		autoMoves:   make(map[string][]time.Time),
+		notifier:    notification.NewNotifier(),
+		clock:       clock,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	d.configManager.Store(configManager)
+	d.scheduler = scheduler.New(clock, d.schedules, scheduler.Handlers{
+		Notify:  d.startCountdown,
+		Execute: d.executeSchedule,
+		Missed:  d.sendMissedNotification,
+		Skipped: d.recordSkipped,
+		Checked: d.saveLastCheck,
+	})
+	return d, nil
+}
+
+func (d *Daemon) config() *config.ConfigManager {
+	return d.configManager.Load()
+}
+
+func (d *Daemon) schedules() []config.Schedule {
+	return d.config().GetEffectiveSchedules()
+}
+
+// Reload reads the config file again, so schedules added, changed or removed
+// by other commands take effect without restarting the daemon.
+func (d *Daemon) Reload() error {
+	d.configMu.Lock()
+	defer d.configMu.Unlock()
+
+	configManager, err := config.NewConfigManager(d.config().Path())
+	if err != nil {
+		return err
 	}
+	d.configManager.Store(configManager)
+	log.Println("Reloaded config")
+
+	d.dropChangedCountdowns()
+	d.dropChangedConnections()
+	d.scheduler.Wake()
+	return nil
 }
 
 func (d *Daemon) Start() error {
@@ -37,7 +129,27 @@ func (d *Daemon) Start() error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	go d.runScheduler()
+	server, err := control.Listen(control.SocketFile(d.config().Path()), d)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	go server.Serve(d.ctx)
+
+	if d.monitor {
+		for _, desk := range d.config().GetAllDesks() {
+			d.connection(desk)
+		}
+	}
+
+	if d.paused() {
+		log.Println("Schedules are paused")
+		d.waitForPauseEnd(d.stateStore.Get().PausedUntil)
+	} else {
+		d.resumeIntervals()
+	}
+	go d.scheduler.Run(d.ctx, d.stateStore.Get().LastCheck)
+	go d.sampleHeights()
 
 	select {
 	case <-sigChan:
@@ -49,76 +161,147 @@ func (d *Daemon) Start() error {
 	}
 }
 
-func (d *Daemon) runScheduler() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
+// resumeIntervals moves the desk of every interval schedule that is inside
+// its window to the current phase, unless the saved state shows the daemon
+// already did so before it was restarted.
+func (d *Daemon) resumeIntervals() {
+	now := d.clock.Now()
+	phases := d.stateStore.Get().Intervals
 
-	for {
-		select {
-		case <-d.ctx.Done():
-			return
-		case <-ticker.C:
-			d.checkSchedules()
+	for _, schedule := range d.schedules() {
+		if !schedule.Enabled || schedule.Kind != config.KindInterval {
+			continue
 		}
-	}
-}
-
-func (d *Daemon) checkSchedules() {
-	schedules := d.configManager.GetSchedules()
-	now := time.Now()
 
-	for _, schedule := range schedules {
-		if !schedule.Enabled {
+		presetName, since, ok := scheduler.IntervalPhase(schedule, now)
+		if !ok {
 			continue
 		}
-
-		if !d.shouldRunToday(schedule, now) {
+		if exclusion, excluded := scheduler.Excluded(schedule, now); excluded {
+			log.Printf("Not resuming interval schedule %s, today is excluded: %s", schedule.Name, exclusion)
 			continue
 		}
 
-		scheduledTime, err := d.parseScheduleTime(schedule.Time, now)
-		if err != nil {
-			log.Printf("Error parsing schedule time for %s: %v", schedule.Name, err)
+		saved, found := phases[schedule.Name]
+		if found && saved.PresetName == presetName && saved.Since.Equal(since) {
+			log.Printf("Resuming interval schedule %s in phase %s since %s", schedule.Name, presetName, since.Format("15:04"))
 			continue
 		}
 
-		timeDiff := scheduledTime.Sub(now)
+		log.Printf("Interval schedule %s should be in phase %s since %s, moving now", schedule.Name, presetName, since.Format("15:04"))
+		d.runSchedule(scheduler.Event{
+			Schedule:   schedule,
+			At:         since,
+			PresetName: presetName,
+		})
+	}
+}
 
-		if timeDiff > 0 && timeDiff <= 10*time.Second {
-			d.sendNotification(schedule)
+// sampleHeights records the height of every monitored desk each
+// SampleInterval, checks the standing goal and trims the history once a day.
+// Other desks are not connected to just for a sample, for them the goal uses
+// the height after their last move.
+func (d *Daemon) sampleHeights() {
+	var trimmed time.Time
+	for {
+		heights := make(map[string]float32)
+		for _, desk := range d.config().GetAllDesks() {
+			var height float32
+			var ok bool
+			if d.monitor {
+				height, ok = d.connection(desk).sample()
+			} else {
+				height, ok = d.lastHeight(desk.Name)
+			}
+			if ok {
+				heights[desk.Name] = height
+			}
 		}
+		d.checkGoal(heights)
 
-		if timeDiff > -30*time.Second && timeDiff <= 0 {
-			d.executeSchedule(schedule)
+		now := d.clock.Now()
+		if now.Sub(trimmed) >= 24*time.Hour {
+			d.trimHistory(now)
+			trimmed = now
+		}
+
+		timer := d.clock.NewTimer(SampleInterval)
+		select {
+		case <-d.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
 		}
 	}
 }
 
-func (d *Daemon) shouldRunToday(schedule config.Schedule, now time.Time) bool {
-	weekday := int(now.Weekday())
+// trimHistory removes the records and samples older than their retention.
+func (d *Daemon) trimHistory(now time.Time) {
+	removed, err := d.runHistory.Trim(now.Add(-history.RecordRetention))
+	if err != nil {
+		log.Printf("Error trimming the move history: %v", err)
+	} else if removed > 0 {
+		log.Printf("Removed %d old records from the move history", removed)
+	}
+
+	removed, err = d.samples.Trim(now.Add(-history.SampleRetention))
+	if err != nil {
+		log.Printf("Error trimming the height samples: %v", err)
+	} else if removed > 0 {
+		log.Printf("Removed %d old height samples", removed)
+	}
+}
 
-	for _, day := range schedule.Days {
-		if day == weekday {
-			return true
-		}
+// Status returns the live state of every configured desk, the moves that are
+// counting down and the pause of the schedules.
+func (d *Daemon) Status() control.Status {
+	desks := d.config().GetAllDesks()
+	names := make([]string, 0, len(desks))
+	for name := range desks {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return false
+	// Desks that were not used yet are not connected to just for the status.
+	states := make([]control.DeskState, 0, len(names))
+	for _, name := range names {
+		conn, ok := d.existingConnection(name)
+		if !ok {
+			states = append(states, control.DeskState{Desk: name, Monitored: d.monitor})
+			continue
+		}
+		states = append(states, conn.status())
+	}
+	return control.Status{
+		Desks:      states,
+		Countdowns: d.Countdowns(),
+		Paused:     d.pause(),
+	}
 }
 
-func (d *Daemon) parseScheduleTime(timeStr string, now time.Time) (time.Time, error) {
-	parsedTime, err := time.Parse("15:04", timeStr)
-	if err != nil {
-		return time.Time{}, err
+// Move moves a desk on request of a client of the control socket.
+func (d *Daemon) Move(ctx context.Context, deskName string, presetName string, updates chan<- float32) (history.Record, error) {
+	if deskName == "" {
+		deskName = d.config().GetDefaultDesk()
 	}
 
-	year, month, day := now.Date()
-	return time.Date(year, month, day, parsedTime.Hour(), parsedTime.Minute(), 0, 0, now.Location()), nil
+	return d.move(ctx, history.Record{
+		Desk:    deskName,
+		Trigger: history.TriggerAPI,
+		Preset:  presetName,
+	}, updates, retryPolicy{}).wait()
 }
 
-func (d *Daemon) sendNotification(schedule config.Schedule) {
-	message := fmt.Sprintf("Your desk will move to preset '%s' in 10 seconds", schedule.PresetName)
-	title := "Desk Movement Scheduled"
+func (d *Daemon) sendMissedNotification(event scheduler.Event) {
+	if d.paused() {
+		return
+	}
+	schedule := event.Schedule
+	message := fmt.Sprintf("Schedule '%s' did not %s at %s because the computer was asleep or the daemon was not running", schedule.Name, event.Describe(), event.At.Format("15:04"))
+	title := "Desk Movement Missed"
+	if !schedule.MovesDesk() {
+		title = "Scheduled Action Missed"
+	}
 
 	err := d.notifier.SendNotification(title, message)
 	if err != nil {
@@ -126,35 +309,77 @@ func (d *Daemon) sendNotification(schedule config.Schedule) {
 	}
 }
 
-func (d *Daemon) executeSchedule(schedule config.Schedule) {
-	log.Printf("Executing schedule: %s", schedule.Name)
-
-	desk, err := d.configManager.GetDesk(schedule.DeskName)
+func (d *Daemon) saveLastCheck(now time.Time) {
+	err := d.stateStore.Update(func(s *state.State) {
+		s.LastCheck = now
+	})
 	if err != nil {
-		log.Printf("Error getting desk %s: %v", schedule.DeskName, err)
+		log.Printf("Error saving daemon state: %v", err)
+	}
+
+}
+
+// executeSchedule runs a due event of the scheduler unless its countdown was
+// cancelled or snoozed.
+func (d *Daemon) executeSchedule(event scheduler.Event) {
+	if !d.endCountdown(event) {
 		return
 	}
+	d.runSchedule(event)
+}
 
-	preset, ok := desk.Presets[schedule.PresetName]
-	if !ok {
-		log.Printf("Error: preset %s not found for desk %s", schedule.PresetName, schedule.DeskName)
+func (d *Daemon) runSchedule(event scheduler.Event) {
+	schedule := event.Schedule
+	if d.paused() {
+		log.Printf("Not executing schedule %s, schedules are paused", schedule.Name)
 		return
 	}
+	if !schedule.MovesDesk() {
+		d.runAction(event)
+		return
+	}
+	if schedule.Kind == config.KindInterval {
+		saved, ok := d.stateStore.Get().Intervals[schedule.Name]
+		if ok && saved.PresetName == event.PresetName && saved.Since.Equal(event.At) {
+			log.Printf("Schedule %s is already in phase %s since %s, not moving", schedule.Name, event.PresetName, event.At.Format("15:04"))
+			return
+		}
+	}
 
-	controller, err := idasen.NewController(desk.Address)
-	if err != nil {
-		log.Printf("Error creating controller for desk %s: %v", schedule.DeskName, err)
+	if reason, ok := d.allowAutoMove(schedule.DeskName); !ok {
+		logLimited("schedule "+schedule.Name, reason)
+		d.recordSkipped(event, reason)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
+	log.Printf("Executing schedule: %s", schedule.Name)
 
-	err = controller.MoveTo(ctx, preset.Height, nil)
-	if err != nil {
-		log.Printf("Error moving desk %s to preset %s: %v", schedule.DeskName, schedule.PresetName, err)
+	// The move runs on the desk's worker, so schedules of other desks due
+	// at the same time are not held up.
+	job := d.moveTo(d.ctx, history.Record{
+		Desk:     schedule.DeskName,
+		Trigger:  history.TriggerSchedule,
+		Schedule: schedule.Name,
+		Preset:   event.PresetName,
+	}, scheduleTarget(event), nil, scheduleRetryPolicy(schedule))
+	if schedule.Kind != config.KindInterval {
 		return
 	}
 
-	log.Printf("Successfully moved desk %s to preset %s (height: %.2f)", schedule.DeskName, schedule.PresetName, preset.Height)
+	go func() {
+		_, err := job.wait()
+		if err != nil {
+			return
+		}
+
+		err = d.stateStore.Update(func(s *state.State) {
+			s.Intervals[schedule.Name] = state.IntervalPhase{
+				PresetName: event.PresetName,
+				Since:      event.At,
+			}
+		})
+		if err != nil {
+			log.Printf("Error saving phase of schedule %s: %v", schedule.Name, err)
+		}
+	}()
 }
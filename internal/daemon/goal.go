@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/goal"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/stats"
+)
+
+// checkGoal reminds about, or moves the desk for, a standing goal that fell
+// behind pace. heights holds the latest sampled height of each desk.
+func (d *Daemon) checkGoal(heights map[string]float32) {
+	standingGoal := d.config().GetGoal()
+	if standingGoal == nil || d.paused() {
+		return
+	}
+
+	deskName := standingGoal.DeskName
+	if deskName == "" {
+		deskName = d.config().GetDefaultDesk()
+	}
+	desk, err := d.config().GetDesk(deskName)
+	if err != nil {
+		log.Printf("Error getting desk %s of the standing goal: %v", deskName, err)
+		return
+	}
+
+	// Already standing, the reminder would not help.
+	if height, ok := heights[desk.Name]; ok && height >= desk.StandingThreshold() {
+		return
+	}
+
+	now := d.clock.Now()
+	remindEvery := standingGoal.RemindEvery
+	if remindEvery <= 0 {
+		remindEvery = config.DefaultRemindEvery
+	}
+	if !d.lastReminder.IsZero() && now.Sub(d.lastReminder) < remindEvery {
+		return
+	}
+
+	progress, err := goal.Load(d.runHistory, d.samples, desk, *standingGoal, now)
+	if err != nil {
+		log.Printf("Error computing standing goal progress: %v", err)
+		return
+	}
+	if !progress.NeedsReminder() {
+		return
+	}
+	d.lastReminder = now
+
+	log.Printf("Standing goal is %s behind pace (%s of %s expected by now)",
+		progress.Behind().Round(time.Minute), progress.Standing.Round(time.Minute), progress.Expected.Round(time.Minute))
+
+	if standingGoal.AutoMove {
+		reason, ok := d.allowAutoMove(desk.Name)
+		if ok {
+			d.move(d.ctx, history.Record{
+				Desk:    desk.Name,
+				Trigger: history.TriggerGoal,
+				Preset:  standingGoal.Preset,
+			}, nil, retryPolicy{})
+			return
+		}
+		// Remind instead.
+		logLimited("the standing goal", reason)
+	}
+
+	message := fmt.Sprintf("You stood %s of your %s goal today, %s behind pace. Time to stand up!",
+		stats.FormatDuration(progress.Standing), stats.FormatDuration(standingGoal.Standing),
+		stats.FormatDuration(progress.Behind()))
+	err = d.notifier.SendNotification("Standing Goal", message)
+	if err != nil {
+		log.Printf("Error sending standing goal reminder: %v", err)
+	}
+}
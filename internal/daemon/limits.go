@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+// quietHours reports whether automatic moves are not allowed right now
+// because of the quiet hours, which are in the default time zone.
+func (d *Daemon) quietHours() bool {
+	loc, err := config.LoadLocation(d.config().GetTimezone())
+	if err != nil {
+		loc = time.Local
+	}
+	return d.config().GetLimits().InQuietHours(d.clock.Now().In(loc))
+}
+
+// allowAutoMove reports whether an automatic move of the desk may run now,
+// and counts it towards the maximum moves per hour when it may. Otherwise
+// it returns the reason.
+func (d *Daemon) allowAutoMove(deskName string) (string, bool) {
+	limits := d.config().GetLimits()
+	if d.quietHours() {
+		return fmt.Sprintf("quiet hours from %s to %s", limits.QuietStart, limits.QuietEnd), false
+	}
+
+	d.autoMovesMu.Lock()
+	defer d.autoMovesMu.Unlock()
+
+	now := d.clock.Now()
+	var recent []time.Time
+	for _, t := range d.autoMoves[deskName] {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	if limits.MaxMovesPerHour > 0 && len(recent) >= limits.MaxMovesPerHour {
+		d.autoMoves[deskName] = recent
+		return fmt.Sprintf("desk already moved automatically %d times in the last hour", len(recent)), false
+	}
+	d.autoMoves[deskName] = append(recent, now)
+	return "", true
+}
+
+// logLimited logs an automatic move that was not made because of the limits.
+func logLimited(what, reason string) {
+	log.Printf("Not moving for %s: %s", what, reason)
+}
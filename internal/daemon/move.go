@@ -0,0 +1,310 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/scheduler"
+)
+
+const (
+	// moveTimeout bounds a single attempt to move a desk.
+	moveTimeout = 2 * time.Minute
+
+	// maxRetryBackoff caps the growing delay between retries.
+	maxRetryBackoff = 5 * time.Minute
+)
+
+var (
+	ErrMoveTimedOut  = errors.New("move timed out")
+	ErrHeightUnknown = errors.New("height of the desk unknown")
+)
+
+// retryPolicy is how often, and how long after a failure, a move is tried
+// again.
+type retryPolicy struct {
+	retries int
+	backoff time.Duration
+}
+
+func scheduleRetryPolicy(schedule config.Schedule) retryPolicy {
+	backoff := schedule.RetryBackoff
+	if backoff <= 0 {
+		backoff = config.DefaultRetryBackoff
+	}
+	return retryPolicy{
+		retries: schedule.Retries,
+		backoff: backoff,
+	}
+}
+
+// moveTarget is where a move takes the desk: a preset, a height, or when
+// toggling whichever of two presets the desk is not at.
+type moveTarget struct {
+	preset      string
+	height      float32
+	sitPreset   string
+	standPreset string
+}
+
+func scheduleTarget(event scheduler.Event) moveTarget {
+	action := event.Schedule.Action
+	switch event.Schedule.ActionKind() {
+	case config.ActionHeight:
+		return moveTarget{height: action.Height}
+	case config.ActionToggle:
+		return moveTarget{sitPreset: action.SitPreset, standPreset: action.StandPreset}
+	}
+	return moveTarget{preset: event.PresetName}
+}
+
+// toggles reports whether the target depends on the height of the desk.
+func (t moveTarget) toggles() bool {
+	return t.sitPreset != ""
+}
+
+// resolve returns the preset the desk at height moves to, a preset without
+// a name for a height. Preset names must match exactly, as a prefix may
+// match another preset once the named one is renamed or deleted.
+func (t moveTarget) resolve(desk config.Desk, height float32) (config.Preset, error) {
+	switch {
+	case t.toggles():
+		if height <= 0 {
+			return config.Preset{}, ErrHeightUnknown
+		}
+		if height >= desk.StandingThreshold() {
+			return config.LookupPreset(desk, t.sitPreset)
+		}
+		return config.LookupPreset(desk, t.standPreset)
+	case t.preset == "":
+		return config.Preset{Height: t.height}, nil
+	}
+	return config.LookupPreset(desk, t.preset)
+}
+
+// describe returns the preset, or the height without one, for messages.
+func describe(preset config.Preset) string {
+	if preset.Name == "" {
+		return fmt.Sprintf("%.2f m", preset.Height)
+	}
+	return "preset " + preset.Name
+}
+
+// move queues a move of record.Desk to record.Preset on the desk's worker.
+// Heights are sent to updates while the desk moves. Failed attempts are
+// retried following retry, and only the final outcome is recorded.
+func (d *Daemon) move(ctx context.Context, record history.Record, updates chan<- float32, retry retryPolicy) *moveJob {
+	return d.moveTo(ctx, record, moveTarget{preset: record.Preset}, updates, retry)
+}
+
+// moveTo is move to any target, which sets record.Preset.
+func (d *Daemon) moveTo(ctx context.Context, record history.Record, target moveTarget, updates chan<- float32, retry retryPolicy) *moveJob {
+	record.Time = d.clock.Now()
+
+	desk, err := d.config().GetDesk(record.Desk)
+	if err != nil {
+		log.Printf("Error getting desk %s: %v", record.Desk, err)
+		record, err = d.finishMove(failed(record, err), err)
+		return finishedJob(record, err)
+	}
+
+	return d.connection(desk).submit(ctx, func(ctx context.Context) (history.Record, error) {
+		return d.finishMove(d.moveWithRetries(ctx, desk, record, target, updates, retry))
+	})
+}
+
+func (d *Daemon) moveWithRetries(ctx context.Context, desk config.Desk, record history.Record, target moveTarget, updates chan<- float32, retry retryPolicy) (history.Record, error) {
+	backoff := retry.backoff
+	for attempt := 1; ; attempt++ {
+		result, err := d.moveToTarget(ctx, desk, record, target, updates)
+		result.Attempts = attempt
+		if err == nil || attempt > retry.retries || !retryable(err) {
+			return result, err
+		}
+
+		log.Printf("Attempt %d of %d to move desk %s failed, retrying in %s: %v",
+			attempt, retry.retries+1, desk.Name, backoff, err)
+
+		timer := d.clock.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			result, err = cancelled(ctx, result)
+			result.Attempts = attempt
+			return result, err
+		case <-timer.C():
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// retryable reports whether a failed move may succeed when tried again.
+func retryable(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, ErrSuperseded),
+		errors.Is(err, config.ErrDeskNotExists),
+		errors.Is(err, config.ErrPresetNotExists),
+		errors.Is(err, config.ErrPresetAmbiguous):
+		return false
+	}
+	return true
+}
+
+// moveToTarget makes a single attempt to move the desk to target.
+func (d *Daemon) moveToTarget(ctx context.Context, desk config.Desk, record history.Record, target moveTarget, updates chan<- float32) (history.Record, error) {
+	if ctx.Err() != nil {
+		return cancelled(ctx, record)
+	}
+
+	// Unless toggling, the target is known before connecting.
+	var preset config.Preset
+	var err error
+	if !target.toggles() {
+		preset, err = target.resolve(desk, 0)
+		if err != nil {
+			log.Printf("Error resolving preset %s of desk %s: %v", target.preset, desk.Name, err)
+			return failed(record, err), err
+		}
+		record.Preset = preset.Name
+	}
+
+	conn := d.connection(desk)
+	controller, err := conn.acquire()
+	if err != nil {
+		log.Printf("Error connecting to desk %s: %v", desk.Name, err)
+		return failed(record, err), err
+	}
+
+	record.FromHeight, err = controller.GetCurrentHeight()
+	if err != nil {
+		log.Printf("Error reading height of desk %s: %v", desk.Name, err)
+		record.FromHeight = 0
+	}
+
+	if target.toggles() {
+		heightErr := err
+		preset, err = target.resolve(desk, record.FromHeight)
+		if err != nil {
+			conn.release(heightErr)
+			log.Printf("Error toggling desk %s: %v", desk.Name, err)
+			return failed(record, err), err
+		}
+		record.Preset = preset.Name
+	}
+
+	moveCtx, cancel := context.WithTimeout(ctx, moveTimeout)
+	defer cancel()
+
+	err = controller.MoveTo(moveCtx, preset.Height, updates)
+	if err != nil {
+		conn.release(err)
+		log.Printf("Error moving desk %s to %s: %v", desk.Name, describe(preset), err)
+		return failed(record, err), err
+	}
+
+	record.ToHeight, err = controller.GetCurrentHeight()
+	conn.release(err)
+	if err != nil {
+		record.ToHeight = 0
+	} else {
+		conn.setHeight(record.ToHeight)
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return cancelled(ctx, record)
+	case moveCtx.Err() != nil:
+		err = fmt.Errorf("%w: the desk did not reach %.2f m within %s", ErrMoveTimedOut, preset.Height, moveTimeout)
+		log.Printf("Error moving desk %s to %s: %v", desk.Name, describe(preset), err)
+		return failed(record, err), err
+	}
+
+	log.Printf("Successfully moved desk %s to %s (height: %.2f)", desk.Name, describe(preset), preset.Height)
+	if record.ToHeight == 0 {
+		record.ToHeight = preset.Height
+	}
+	record.Outcome = history.OutcomeSuccess
+	record.Reason = ""
+	return record, nil
+}
+
+// finishMove records the final outcome of a move and, for moves nobody is
+// waiting on, notifies about a failure.
+func (d *Daemon) finishMove(record history.Record, err error) (history.Record, error) {
+	record = d.recordRun(record)
+
+	if record.Outcome == history.OutcomeFailed && record.Trigger != history.TriggerAPI {
+		d.sendFailureNotification(record)
+	}
+	return record, err
+}
+
+func (d *Daemon) sendFailureNotification(record history.Record) {
+	target := record.Desk
+	if record.Preset != "" {
+		target = fmt.Sprintf("%s to preset '%s'", record.Desk, record.Preset)
+	}
+
+	message := fmt.Sprintf("Could not move desk %s: %s", target, record.Reason)
+	if record.Schedule != "" {
+		message = fmt.Sprintf("Schedule '%s' could not move desk %s: %s", record.Schedule, target, record.Reason)
+	}
+	if record.Attempts > 1 {
+		message += fmt.Sprintf(" (after %d attempts)", record.Attempts)
+	}
+
+	err := d.notifier.SendNotification("Desk Movement Failed", message)
+	if err != nil {
+		log.Printf("Error sending failure notification for desk %s: %v", record.Desk, err)
+	}
+}
+
+func (d *Daemon) recordSkipped(event scheduler.Event, reason string) {
+	// The history is ordered by Time, while a run may be handled long
+	// after it was due.
+	d.recordRun(history.Record{
+		Time:        d.clock.Now(),
+		ScheduledAt: event.At,
+		Desk:        event.Schedule.DeskName,
+		Trigger:     history.TriggerSchedule,
+		Schedule:    event.Schedule.Name,
+		Preset:      event.PresetName,
+		Outcome:     history.OutcomeSkipped,
+		Reason:      reason,
+	})
+}
+
+func (d *Daemon) recordRun(record history.Record) history.Record {
+	if record.Outcome != history.OutcomeSkipped {
+		record.DurationMs = d.clock.Now().Sub(record.Time).Milliseconds()
+	}
+	err := d.runHistory.Append(record)
+	if err != nil {
+		log.Printf("Error recording move of desk %s: %v", record.Desk, err)
+	}
+	return record
+}
+
+func cancelled(ctx context.Context, record history.Record) (history.Record, error) {
+	cause := context.Cause(ctx)
+	log.Printf("Move of desk %s was cancelled: %v", record.Desk, cause)
+
+	record.Outcome = history.OutcomeCancelled
+	record.Reason = ""
+	if !errors.Is(cause, context.Canceled) {
+		record.Reason = cause.Error()
+	}
+	return record, cause
+}
+
+func failed(record history.Record, err error) history.Record {
+	record.Outcome = history.OutcomeFailed
+	record.Reason = err.Error()
+	return record
+}
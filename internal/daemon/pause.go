@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/control"
+	"github.com/samueltorres/idasenctl/internal/state"
+)
+
+// paused reports whether the scheduled moves are paused right now.
+func (d *Daemon) paused() bool {
+	return d.stateStore.Get().PausedAt(d.clock.Now())
+}
+
+// pause returns the current pause, nil when the schedules run.
+func (d *Daemon) pause() *control.Pause {
+	s := d.stateStore.Get()
+	if !s.PausedAt(d.clock.Now()) {
+		return nil
+	}
+	return &control.Pause{Until: s.PausedUntil}
+}
+
+// Pause stops the scheduled moves until the given time, or until Resume when
+// until is zero. Moves that are counting down are dropped.
+func (d *Daemon) Pause(until time.Time) error {
+	err := d.stateStore.Update(func(s *state.State) {
+		s.Paused = true
+		s.PausedUntil = until
+	})
+	if err != nil {
+		return err
+	}
+
+	d.waitForPauseEnd(until)
+	if until.IsZero() {
+		log.Println("Paused schedules until resumed")
+	} else {
+		log.Printf("Paused schedules until %s", until.Format("2006-01-02 15:04"))
+	}
+
+	d.countdownsMu.Lock()
+	defer d.countdownsMu.Unlock()
+	for name, c := range d.countdowns {
+		c.dismiss()
+		delete(d.countdowns, name)
+	}
+	return nil
+}
+
+// Resume ends a pause of the scheduled moves and moves the desks of interval
+// schedules to the phase they should be in.
+func (d *Daemon) Resume() error {
+	err := d.stateStore.Update(func(s *state.State) {
+		s.Paused = false
+		s.PausedUntil = time.Time{}
+	})
+	if err != nil {
+		return err
+	}
+
+	d.waitForPauseEnd(time.Time{})
+	log.Println("Resumed schedules")
+	d.resumeIntervals()
+	d.scheduler.Wake()
+	return nil
+}
+
+// waitForPauseEnd resumes the schedules like Resume once the pause until the
+// given time ends, replacing the wait for an earlier pause. A zero until
+// only stops that wait.
+func (d *Daemon) waitForPauseEnd(until time.Time) {
+	d.pauseEndMu.Lock()
+	defer d.pauseEndMu.Unlock()
+
+	if d.pauseEnd != nil {
+		close(d.pauseEnd)
+		d.pauseEnd = nil
+	}
+	if until.IsZero() {
+		return
+	}
+	stop := make(chan struct{})
+	d.pauseEnd = stop
+
+	go func() {
+		// Wake up at least every minute, as timers do not run while the
+		// computer is suspended.
+		for now := d.clock.Now(); now.Before(until); now = d.clock.Now() {
+			timer := d.clock.NewTimer(min(until.Sub(now), time.Minute))
+			select {
+			case <-d.ctx.Done():
+				timer.Stop()
+				return
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+		}
+		d.endPause(until)
+	}()
+}
+
+// endPause ends the pause until the given time unless it was replaced.
+func (d *Daemon) endPause(until time.Time) {
+	ended := false
+	err := d.stateStore.Update(func(s *state.State) {
+		if s.Paused && s.PausedUntil.Equal(until) {
+			s.Paused = false
+			s.PausedUntil = time.Time{}
+			ended = true
+		}
+	})
+	if err != nil {
+		log.Printf("Error ending the pause of the schedules: %v", err)
+		return
+	}
+	if !ended {
+		return
+	}
+
+	log.Println("Pause ended, resumed schedules")
+	d.resumeIntervals()
+	d.scheduler.Wake()
+}
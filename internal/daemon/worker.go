@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+
+	"github.com/samueltorres/idasenctl/internal/history"
+)
+
+// ErrSuperseded cancels a move when a newer one for the same desk arrives.
+var ErrSuperseded = errors.New("superseded by a newer move of the desk")
+
+// moveJob is a move waiting for or running on a desk's worker.
+type moveJob struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	run    func(ctx context.Context) (history.Record, error)
+	done   chan struct{}
+	record history.Record
+	err    error
+}
+
+func newMoveJob(ctx context.Context, run func(ctx context.Context) (history.Record, error)) *moveJob {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &moveJob{
+		ctx:    ctx,
+		cancel: cancel,
+		run:    run,
+		done:   make(chan struct{}),
+	}
+}
+
+// finishedJob returns a job that already ended with the given result.
+func finishedJob(record history.Record, err error) *moveJob {
+	job := &moveJob{
+		done:   make(chan struct{}),
+		record: record,
+		err:    err,
+	}
+	close(job.done)
+	return job
+}
+
+// wait blocks until the job finished and returns its result.
+func (j *moveJob) wait() (history.Record, error) {
+	<-j.done
+	return j.record, j.err
+}
+
+// submit hands a move to the desk's worker. Moves of one desk run one at a
+// time in the order they were submitted, and each one cancels the moves
+// before it that are still running or waiting.
+func (c *deskConnection) submit(ctx context.Context, run func(ctx context.Context) (history.Record, error)) *moveJob {
+	job := newMoveJob(ctx, run)
+
+	c.mu.Lock()
+	if c.latest != nil {
+		c.latest.cancel(ErrSuperseded)
+	}
+	c.latest = job
+	c.mu.Unlock()
+
+	c.submitMu.Lock()
+	defer c.submitMu.Unlock()
+	if c.removed {
+		job.cancel(ErrDeskChanged)
+		go job.finish()
+		return job
+	}
+	c.queue <- job
+	return job
+}
+
+// work runs the submitted moves until the daemon stops or the desk is
+// removed, which cancels the moves still waiting. Cancelled moves are still
+// handed to their run function, which records them as such.
+func (c *deskConnection) work() {
+	for {
+		select {
+		case <-c.d.ctx.Done():
+			return
+		case <-c.stopped:
+			for {
+				select {
+				case job := <-c.queue:
+					job.cancel(ErrDeskChanged)
+					job.finish()
+				default:
+					return
+				}
+			}
+		case job := <-c.queue:
+			job.finish()
+		}
+	}
+}
+
+// finish runs the job and reports its result to the waiters.
+func (j *moveJob) finish() {
+	j.record, j.err = j.run(j.ctx)
+	j.cancel(nil)
+	close(j.done)
+}
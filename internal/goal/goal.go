@@ -0,0 +1,137 @@
+package goal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/history"
+	"github.com/samueltorres/idasenctl/internal/stats"
+)
+
+var (
+	ErrInvalidGoal = errors.New("invalid standing goal")
+)
+
+// Validate checks the amount and work hours of a goal.
+func Validate(goal config.Goal) error {
+	if goal.Standing <= 0 {
+		return fmt.Errorf("%w: the standing time must be positive", ErrInvalidGoal)
+	}
+	if goal.BehindBy <= 0 {
+		return fmt.Errorf("%w: the allowed lag must be positive", ErrInvalidGoal)
+	}
+
+	start, err := time.Parse("15:04", goal.Start)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start %q", ErrInvalidGoal, goal.Start)
+	}
+	end, err := time.Parse("15:04", goal.End)
+	if err != nil {
+		return fmt.Errorf("%w: invalid end %q", ErrInvalidGoal, goal.End)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("%w: work hours end %s must be after their start %s", ErrInvalidGoal, goal.End, goal.Start)
+	}
+	if goal.Standing > end.Sub(start) {
+		return fmt.Errorf("%w: %s of standing does not fit in the work hours %s-%s", ErrInvalidGoal, goal.Standing, goal.Start, goal.End)
+	}
+	if goal.AutoMove && goal.Preset == "" {
+		return fmt.Errorf("%w: auto-move requires a preset", ErrInvalidGoal)
+	}
+
+	return nil
+}
+
+// Progress is how much of the goal was reached on a day.
+type Progress struct {
+	Goal     config.Goal
+	Standing time.Duration
+	// Expected is how much standing the goal asks for by the time of the
+	// progress, spread evenly over the work hours.
+	Expected time.Duration
+	// WorkDay is false on days the goal does not apply to.
+	WorkDay bool
+	// InWorkHours is true when the progress was taken during work hours.
+	InWorkHours bool
+}
+
+// Behind returns how far the standing time lags the expected pace.
+func (p Progress) Behind() time.Duration {
+	return max(p.Expected-p.Standing, 0)
+}
+
+func (p Progress) Done() bool {
+	return p.Standing >= p.Goal.Standing
+}
+
+// Ratio returns the share of the goal reached, at most 1.
+func (p Progress) Ratio() float64 {
+	return min(float64(p.Standing)/float64(p.Goal.Standing), 1)
+}
+
+// NeedsReminder reports whether progress fell behind pace by more than the
+// goal allows during work hours.
+func (p Progress) NeedsReminder() bool {
+	return p.WorkDay && p.InWorkHours && !p.Done() && p.Behind() >= p.Goal.BehindBy
+}
+
+// Compute returns the progress at now for the given standing time today.
+func Compute(goal config.Goal, standing time.Duration, now time.Time) Progress {
+	progress := Progress{
+		Goal:     goal,
+		Standing: standing,
+		WorkDay:  len(goal.Days) == 0 || slices.Contains(goal.Days, int(now.Weekday())),
+	}
+	if !progress.WorkDay {
+		return progress
+	}
+
+	start, end, err := workHours(goal, now)
+	if err != nil {
+		return progress
+	}
+
+	progress.InWorkHours = !now.Before(start) && now.Before(end)
+	switch {
+	case now.Before(start):
+		progress.Expected = 0
+	case !now.Before(end):
+		progress.Expected = goal.Standing
+	default:
+		progress.Expected = time.Duration(float64(goal.Standing) * float64(now.Sub(start)) / float64(end.Sub(start)))
+	}
+
+	return progress
+}
+
+// Load computes today's progress of the goal from the recorded samples and
+// moves of the desk.
+func Load(runs *history.Store, samples *history.SampleStore, desk config.Desk, goal config.Goal, now time.Time) (Progress, error) {
+	year, month, day := now.Date()
+	from := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	report, err := stats.Load(runs, samples, desk, from, now, stats.DefaultMaxGap)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	return Compute(goal, report.Total().Standing, now), nil
+}
+
+func workHours(goal config.Goal, date time.Time) (time.Time, time.Time, error) {
+	start, err := time.Parse("15:04", goal.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse("15:04", goal.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	year, month, day := date.Date()
+	return time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, date.Location()),
+		time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, date.Location()), nil
+}
@@ -0,0 +1,321 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/atomicfile"
+	"github.com/samueltorres/idasenctl/internal/filelock"
+)
+
+var (
+	ErrInvalidTrigger = errors.New("invalid trigger, must be one of schedule, cli, api or goal")
+	ErrInvalidOutcome = errors.New("invalid outcome, must be one of success, failed, skipped or cancelled")
+)
+
+const (
+	// RecordRetention is how long records are kept by Trim.
+	RecordRetention = 365 * 24 * time.Hour
+
+	// seekMargin is how much earlier than the start of a query reading
+	// starts, as entries are appended when they end and may be slightly out
+	// of order.
+	seekMargin = time.Hour
+)
+
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerCLI      Trigger = "cli"
+	TriggerAPI      Trigger = "api"
+	TriggerGoal     Trigger = "goal"
+)
+
+func ParseTrigger(s string) (Trigger, error) {
+	switch Trigger(strings.ToLower(s)) {
+	case TriggerSchedule:
+		return TriggerSchedule, nil
+	case TriggerCLI:
+		return TriggerCLI, nil
+	case TriggerAPI:
+		return TriggerAPI, nil
+	case TriggerGoal:
+		return TriggerGoal, nil
+	}
+	return "", ErrInvalidTrigger
+}
+
+type Outcome string
+
+const (
+	OutcomeSuccess   Outcome = "success"
+	OutcomeFailed    Outcome = "failed"
+	OutcomeSkipped   Outcome = "skipped"
+	OutcomeCancelled Outcome = "cancelled"
+)
+
+func ParseOutcome(s string) (Outcome, error) {
+	switch Outcome(strings.ToLower(s)) {
+	case OutcomeSuccess:
+		return OutcomeSuccess, nil
+	case OutcomeFailed:
+		return OutcomeFailed, nil
+	case OutcomeSkipped:
+		return OutcomeSkipped, nil
+	case OutcomeCancelled:
+		return OutcomeCancelled, nil
+	}
+	return "", ErrInvalidOutcome
+}
+
+// Record is a single desk move, or a scheduled move that did not happen.
+type Record struct {
+	Time        time.Time `json:"time"`
+	ScheduledAt time.Time `json:"scheduledAt,omitzero"` // When the run of a schedule was due, for runs recorded later
+	Desk        string    `json:"desk"`
+	Trigger     Trigger   `json:"trigger"`
+	Schedule    string    `json:"schedule,omitempty"`
+	Preset      string    `json:"preset,omitempty"`
+	FromHeight  float32   `json:"fromHeight,omitempty"`
+	ToHeight    float32   `json:"toHeight,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+	Attempts    int       `json:"attempts,omitempty"`
+	Outcome     Outcome   `json:"outcome"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+func (r Record) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+// Filter selects records in Query. Zero fields match everything.
+type Filter struct {
+	Desk     string
+	Trigger  Trigger
+	Outcome  Outcome
+	Schedule string
+	Since    time.Time
+	Until    time.Time
+	// Limit keeps only the most recent records.
+	Limit int
+}
+
+func (f Filter) matches(r Record) bool {
+	switch {
+	case f.Desk != "" && r.Desk != f.Desk:
+		return false
+	case f.Trigger != "" && r.Trigger != f.Trigger:
+		return false
+	case f.Outcome != "" && r.Outcome != f.Outcome:
+		return false
+	case f.Schedule != "" && r.Schedule != f.Schedule:
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && r.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Store is an append-only log of records, one JSON document per line.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// HistoryFile returns the path of the run history kept next to configFile.
+func HistoryFile(configFile string) string {
+	return configFile + ".runs.jsonl"
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+func (s *Store) Append(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return appendLine(s.path, r)
+}
+
+// Query returns the records matching filter, oldest first.
+func (s *Store) Query(filter Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := readLines(s.path, filter.Since, recordTime, filter.matches)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
+
+// Trim removes the records from before the given time and returns how many
+// were removed.
+func (s *Store) Trim(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return trimLines(s.path, func(r Record) bool {
+		return !r.Time.Before(before)
+	})
+}
+
+func recordTime(r Record) time.Time {
+	return r.Time
+}
+
+func appendLine[T any](path string, v T) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Join(err, errors.New("could not encode history entry"))
+	}
+
+	// Other processes may append or trim the file at the same time.
+	unlock, err := filelock.Lock(path)
+	if err != nil {
+		return errors.Join(err, errors.New("could not lock history file"))
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Join(err, errors.New("could not open history file"))
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		return errors.Join(err, errors.New("could not write history file"))
+	}
+
+	return nil
+}
+
+// readLines returns the entries of the file that keep accepts. When since is
+// set, reading starts close before the first entry from since on instead of
+// at the start of the file.
+func readLines[T any](path string, since time.Time, timeOf func(T) time.Time, keep func(T) bool) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not open history file"))
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !since.IsZero() {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, errors.Join(err, errors.New("could not read history file"))
+		}
+		offset := seekLine(f, info.Size(), since.Add(-seekMargin), timeOf)
+		r = io.NewSectionReader(f, offset, info.Size()-offset)
+	}
+
+	var entries []T
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var v T
+		// Skip lines that cannot be parsed, such as one cut short by a crash
+		// or the partial line reading started in.
+		if json.Unmarshal(scanner.Bytes(), &v) != nil {
+			continue
+		}
+		if keep(v) {
+			entries = append(entries, v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(err, errors.New("could not read history file"))
+	}
+
+	return entries, nil
+}
+
+// seekLine returns an offset before the first line of the file logged at or
+// after t, found by bisecting the file, which is ordered by time.
+func seekLine[T any](f *os.File, size int64, t time.Time, timeOf func(T) time.Time) int64 {
+	lo, hi := int64(0), size
+	for hi-lo > 4096 {
+		mid := lo + (hi-lo)/2
+		at, ok := lineTimeAfter(f, mid, size, timeOf)
+		if ok && at.Before(t) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// lineTimeAfter returns the time of the first complete line that can be
+// parsed after offset.
+func lineTimeAfter[T any](f *os.File, offset int64, size int64, timeOf func(T) time.Time) (time.Time, bool) {
+	scanner := bufio.NewScanner(io.NewSectionReader(f, offset, size-offset))
+	// The first line is most likely partial.
+	scanner.Scan()
+	for scanner.Scan() {
+		var v T
+		if json.Unmarshal(scanner.Bytes(), &v) == nil {
+			return timeOf(v), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// trimLines rewrites the file with only the lines that keep accepts, dropping
+// lines that cannot be parsed, and returns how many lines were removed.
+func trimLines[T any](path string, keep func(T) bool) (int, error) {
+	// Lines appended after reading the file would be lost on replacing it.
+	unlock, err := filelock.Lock(path)
+	if err != nil {
+		return 0, errors.Join(err, errors.New("could not lock history file"))
+	}
+	defer unlock()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Join(err, errors.New("could not read history file"))
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	for line := range bytes.Lines(b) {
+		var v T
+		if json.Unmarshal(line, &v) != nil || !keep(v) {
+			removed++
+			continue
+		}
+		kept.Write(line)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	err = atomicfile.Write(path, kept.Bytes(), 0644)
+	if err != nil {
+		return 0, errors.Join(err, errors.New("could not write history file"))
+	}
+
+	return removed, nil
+}
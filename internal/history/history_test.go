@@ -0,0 +1,122 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSampleQuerySince(t *testing.T) {
+	store := NewSampleStore(filepath.Join(t.TempDir(), "heights.jsonl"))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 10000 {
+		err := store.Append(Sample{Time: start.Add(time.Duration(i) * 5 * time.Minute), Desk: "desk", Height: 0.7})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, since := range []time.Time{
+		start.Add(-time.Hour),
+		start,
+		start.Add(5000*5*time.Minute + time.Second),
+		start.Add(9999 * 5 * time.Minute),
+		start.Add(10000 * 5 * time.Minute),
+	} {
+		samples, err := store.Query("", since, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := 0
+		for i := range 10000 {
+			if !start.Add(time.Duration(i) * 5 * time.Minute).Before(since) {
+				want++
+			}
+		}
+		if len(samples) != want {
+			t.Errorf("Query since %s returned %d samples, want %d", since, len(samples), want)
+		}
+	}
+}
+
+func TestStoreTrim(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "runs.jsonl"))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		err := store.Append(Record{Time: start.Add(time.Duration(i) * time.Hour), Desk: "desk", Outcome: OutcomeSuccess})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := store.Trim(start.Add(4 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 4 {
+		t.Errorf("Trim removed %d records, want 4", removed)
+	}
+
+	records, err := store.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 || !records[0].Time.Equal(start.Add(4*time.Hour)) {
+		t.Errorf("after Trim got %d records starting at %v, want 6 starting at %v", len(records), records[0].Time, start.Add(4*time.Hour))
+	}
+
+	err = store.Append(Record{Time: start.Add(10 * time.Hour), Desk: "desk", Outcome: OutcomeSuccess})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = store.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 7 {
+		t.Errorf("after appending to the trimmed history got %d records, want 7", len(records))
+	}
+}
+
+func TestTrimKeepsConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	// Separate stores share no mutex, like the daemon and a CLI process.
+	appender, trimmer := NewStore(path), NewStore(path)
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 300 {
+			for _, at := range []time.Time{old, recent.Add(time.Duration(i) * time.Second)} {
+				err := appender.Append(Record{Time: at, Desk: "desk", Outcome: OutcomeSuccess})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}
+	}()
+
+	for trimming := true; trimming; {
+		select {
+		case <-done:
+			trimming = false
+		default:
+		}
+		_, err := trimmer.Trim(recent)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := appender.Query(Filter{Since: recent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 300 {
+		t.Errorf("got %d records appended while trimming, want 300", len(records))
+	}
+}
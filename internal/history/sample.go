@@ -0,0 +1,72 @@
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// SampleRetention is how long samples are kept by Trim.
+const SampleRetention = 90 * 24 * time.Hour
+
+// Sample is the height of a desk observed at a point in time.
+type Sample struct {
+	Time   time.Time `json:"time"`
+	Desk   string    `json:"desk"`
+	Height float32   `json:"height"`
+}
+
+// SampleStore is an append-only log of height samples, one JSON document per
+// line.
+type SampleStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// SamplesFile returns the path of the height samples kept next to configFile.
+func SamplesFile(configFile string) string {
+	return configFile + ".heights.jsonl"
+}
+
+func NewSampleStore(path string) *SampleStore {
+	return &SampleStore{path: path}
+}
+
+func (s *SampleStore) Append(sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return appendLine(s.path, sample)
+}
+
+// Query returns the samples of the desk taken within [since, until], oldest
+// first. Zero values match everything.
+func (s *SampleStore) Query(desk string, since time.Time, until time.Time) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return readLines(s.path, since, sampleTime, func(sample Sample) bool {
+		switch {
+		case desk != "" && sample.Desk != desk:
+			return false
+		case !since.IsZero() && sample.Time.Before(since):
+			return false
+		case !until.IsZero() && sample.Time.After(until):
+			return false
+		}
+		return true
+	})
+}
+
+// Trim removes the samples from before the given time and returns how many
+// were removed.
+func (s *SampleStore) Trim(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return trimLines(s.path, func(sample Sample) bool {
+		return !sample.Time.Before(before)
+	})
+}
+
+func sampleTime(sample Sample) time.Time {
+	return sample.Time
+}
@@ -0,0 +1,319 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	ErrUnsupportedRule = errors.New("unsupported recurrence")
+)
+
+// Event is a VEVENT of an iCalendar file.
+type Event struct {
+	Summary string
+	Start   time.Time
+	// End is exclusive, the day after the last day for all-day events.
+	End    time.Time
+	AllDay bool
+	// Rule is the RRULE of a recurring event, and RDates and ExDates are the
+	// starts of its extra and excluded occurrences.
+	Rule    string
+	RDates  []time.Time
+	ExDates []time.Time
+	// unsupported describes a recurrence that cannot be expanded.
+	unsupported string
+}
+
+// Parse reads the events of an iCalendar (.ics) file. Recurring events are
+// returned once, use Occurrences to expand them.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	for _, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+		case name == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, errors.Join(ErrInvalidCalendar, errors.New("END:VEVENT without BEGIN:VEVENT"))
+			}
+			if event.Start.IsZero() {
+				return nil, errors.Join(ErrInvalidCalendar, errors.New("event without DTSTART"))
+			}
+			if event.End.IsZero() {
+				event.End = event.Start
+				if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+			}
+			events = append(events, *event)
+			event = nil
+		case event == nil:
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+		case name == "DTSTART":
+			event.Start, event.AllDay, err = parseTime(value, params)
+			if err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			event.End, _, err = parseTime(value, params)
+			if err != nil {
+				return nil, err
+			}
+		case name == "RRULE":
+			event.Rule = value
+		case name == "RDATE" && params["VALUE"] == "PERIOD":
+			event.unsupported = "RDATE with periods"
+		case name == "RDATE" || name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, err := parseTime(v, params)
+				if err != nil {
+					return nil, err
+				}
+				if name == "RDATE" {
+					event.RDates = append(event.RDates, t)
+				} else {
+					event.ExDates = append(event.ExDates, t)
+				}
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// Recurring reports whether the event has more than one occurrence.
+func (e Event) Recurring() bool {
+	return e.Rule != "" || len(e.RDates) > 0 || e.unsupported != ""
+}
+
+// maxRuleSteps bounds how many steps of a recurrence rule Occurrences takes,
+// counted from the start of the event.
+const maxRuleSteps = 100000
+
+// Occurrences returns the occurrences of the event that overlap from until
+// until, earliest first. Rules repeating yearly, monthly, weekly or daily
+// are expanded, with an INTERVAL, COUNT or UNTIL, and BYMONTH, BYMONTHDAY
+// and BYDAY only when they repeat the day of the start.
+func (e Event) Occurrences(from, until time.Time) ([]Event, error) {
+	if e.unsupported != "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRule, e.unsupported)
+	}
+
+	starts := []time.Time{e.Start}
+	if e.Rule != "" {
+		r, err := parseRule(e.Rule, e.Start)
+		if err != nil {
+			return nil, err
+		}
+		starts = r.starts(e.Start, until)
+	}
+	starts = append(starts, e.RDates...)
+
+	var occurrences []Event
+	for _, start := range starts {
+		if slices.ContainsFunc(e.ExDates, start.Equal) {
+			continue
+		}
+		if slices.ContainsFunc(occurrences, func(o Event) bool { return o.Start.Equal(start) }) {
+			continue
+		}
+
+		occurrence := Event{Summary: e.Summary, Start: start, AllDay: e.AllDay}
+		if e.AllDay {
+			days := int(e.End.Sub(e.Start).Round(24*time.Hour) / (24 * time.Hour))
+			occurrence.End = start.AddDate(0, 0, days)
+		} else {
+			occurrence.End = start.Add(e.End.Sub(e.Start))
+		}
+		if occurrence.End.After(from) && occurrence.Start.Before(until) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	slices.SortFunc(occurrences, func(a, b Event) int { return a.Start.Compare(b.Start) })
+	return occurrences, nil
+}
+
+// rule is a parsed RRULE.
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+}
+
+var weekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func parseRule(value string, start time.Time) (rule, error) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+			if !slices.Contains([]string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY"}, r.freq) {
+				return rule{}, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, v)
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = errors.New("INTERVAL must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+			if err == nil && r.count < 1 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			r.until, _, err = parseTime(v, nil)
+		case "WKST":
+		case "BYMONTH":
+			if v != strconv.Itoa(int(start.Month())) {
+				return rule{}, fmt.Errorf("%w: BYMONTH=%s", ErrUnsupportedRule, v)
+			}
+		case "BYMONTHDAY":
+			if v != strconv.Itoa(start.Day()) {
+				return rule{}, fmt.Errorf("%w: BYMONTHDAY=%s", ErrUnsupportedRule, v)
+			}
+		case "BYDAY":
+			if strings.ToUpper(v) != weekdays[start.Weekday()] {
+				return rule{}, fmt.Errorf("%w: BYDAY=%s", ErrUnsupportedRule, v)
+			}
+		default:
+			return rule{}, fmt.Errorf("%w: %s", ErrUnsupportedRule, part)
+		}
+		if err != nil {
+			return rule{}, errors.Join(ErrInvalidCalendar, fmt.Errorf("invalid %s: %w", part, err))
+		}
+	}
+	if r.freq == "" {
+		return rule{}, errors.Join(ErrInvalidCalendar, errors.New("RRULE without FREQ"))
+	}
+	return r, nil
+}
+
+// starts returns the starts of the occurrences of the rule before until.
+// Dates that do not exist, like February 30, are skipped.
+func (r rule) starts(start, until time.Time) []time.Time {
+	var starts []time.Time
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	for step := 0; step < maxRuleSteps; step++ {
+		n := step * r.interval
+		var at time.Time
+		switch r.freq {
+		case "YEARLY":
+			at = time.Date(year+n, month, day, hour, minute, second, 0, start.Location())
+		case "MONTHLY":
+			at = time.Date(year, month+time.Month(n), day, hour, minute, second, 0, start.Location())
+		case "WEEKLY":
+			at = time.Date(year, month, day+7*n, hour, minute, second, 0, start.Location())
+		case "DAILY":
+			at = time.Date(year, month, day+n, hour, minute, second, 0, start.Location())
+		}
+		if at.Day() != day && (r.freq == "YEARLY" || r.freq == "MONTHLY") {
+			continue
+		}
+		if !at.Before(until) || (!r.until.IsZero() && at.After(r.until)) || (r.count > 0 && len(starts) == r.count) {
+			break
+		}
+		starts = append(starts, at)
+	}
+	return starts
+}
+
+// unfold joins the continuation lines, which start with a space or tab,
+// to the line before them.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(err, errors.New("could not read calendar"))
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line like DTSTART;VALUE=DATE:20261225.
+func parseLine(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ':' && !quoted:
+			name, rawParams, _ := strings.Cut(line[:i], ";")
+			params := make(map[string]string)
+			for _, param := range strings.Split(rawParams, ";") {
+				if key, value, ok := strings.Cut(param, "="); ok {
+					params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+				}
+			}
+			return strings.ToUpper(name), params, line[i+1:], true
+		}
+	}
+	return "", nil, "", false
+}
+
+func parseTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, errors.Join(ErrInvalidCalendar, err)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, errors.Join(ErrInvalidCalendar, err)
+		}
+		return t.Local(), false, nil
+	}
+
+	location := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	if err != nil {
+		return time.Time{}, false, errors.Join(ErrInvalidCalendar, err)
+	}
+	return t.Local(), false, nil
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,94 @@
+package ical
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	until := time.Date(2029, 1, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		lines string
+		want  []string
+		err   error
+	}{
+		{
+			name:  "yearly",
+			lines: "DTSTART;VALUE=DATE:20201225\nRRULE:FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25",
+			want:  []string{"2026-12-25", "2027-12-25", "2028-12-25"},
+		},
+		{
+			name:  "yearly on February 29",
+			lines: "DTSTART;VALUE=DATE:20240229\nRRULE:FREQ=YEARLY",
+			want:  []string{"2028-02-29"},
+		},
+		{
+			name:  "yearly with count",
+			lines: "DTSTART;VALUE=DATE:20250501\nRRULE:FREQ=YEARLY;COUNT=3",
+			want:  []string{"2026-05-01", "2027-05-01"},
+		},
+		{
+			name:  "yearly until",
+			lines: "DTSTART;VALUE=DATE:20250501\nRRULE:FREQ=YEARLY;UNTIL=20270501",
+			want:  []string{"2026-05-01", "2027-05-01"},
+		},
+		{
+			name:  "daily with interval and count",
+			lines: "DTSTART;VALUE=DATE:20270301\nRRULE:FREQ=DAILY;INTERVAL=2;COUNT=3",
+			want:  []string{"2027-03-01", "2027-03-03", "2027-03-05"},
+		},
+		{
+			name:  "monthly skips missing days",
+			lines: "DTSTART;VALUE=DATE:20280131\nRRULE:FREQ=MONTHLY;COUNT=3",
+			want:  []string{"2028-01-31", "2028-03-31", "2028-05-31"},
+		},
+		{
+			name:  "extra and excluded dates",
+			lines: "DTSTART;VALUE=DATE:20260601\nRRULE:FREQ=YEARLY\nEXDATE;VALUE=DATE:20270601\nRDATE;VALUE=DATE:20270602,20260601",
+			want:  []string{"2026-06-01", "2027-06-02", "2028-06-01"},
+		},
+		{
+			name:  "nth weekday",
+			lines: "DTSTART;VALUE=DATE:20201126\nRRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			err:   ErrUnsupportedRule,
+		},
+		{
+			name:  "hourly",
+			lines: "DTSTART;VALUE=DATE:20201126\nRRULE:FREQ=HOURLY",
+			err:   ErrUnsupportedRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\n" + tt.lines + "\nEND:VEVENT\nEND:VCALENDAR\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !events[0].Recurring() {
+				t.Error("event is not recurring")
+			}
+
+			occurrences, err := events[0].Occurrences(from, until)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Occurrences error = %v, want %v", err, tt.err)
+			}
+			var got []string
+			for _, occurrence := range occurrences {
+				got = append(got, occurrence.Start.Format("2006-01-02"))
+				if days := occurrence.End.Sub(occurrence.Start).Round(time.Hour); days != 24*time.Hour {
+					t.Errorf("occurrence on %s lasts %s, want a day", got[len(got)-1], days)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Occurrences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/samueltorres/idasenctl/internal/ble"
 )
@@ -22,8 +24,14 @@ var (
 
 	ErrHeightBiggerThanMax  = errors.New("height is bigger than the max height")
 	ErrHeightSmallerThanMin = errors.New("height is smaller than the min height")
+	ErrDeskUnreachable      = errors.New("desk unreachable")
+	ErrStalled              = errors.New("desk stalled")
 )
 
+// stallTimeout is how long the desk may stay at the same height during a
+// move, for example when it hit an obstacle, before MoveTo gives up.
+const stallTimeout = 5 * time.Second
+
 type Controller struct {
 	adaptor *ble.Adapter
 }
@@ -31,7 +39,7 @@ type Controller struct {
 func NewController(deskAddress string) (*Controller, error) {
 	bleAdaptor, err := ble.NewAdapter(deskAddress)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrDeskUnreachable, err)
 	}
 
 	return &Controller{
@@ -39,18 +47,32 @@ func NewController(deskAddress string) (*Controller, error) {
 	}, nil
 }
 
-func (c *Controller) MoveTo(ctx context.Context, desiredHeight float32, updates chan<- float32) error {
-	if desiredHeight > float32(IDASEN_MAX_HEIGHT) {
-		return ErrHeightBiggerThanMax
+// ValidateHeight checks that the height, in meters, is within the range the
+// desk can move to.
+func ValidateHeight(height float32) error {
+	if height > float32(IDASEN_MAX_HEIGHT) {
+		return fmt.Errorf("%w (%.2f m > %.2f m)", ErrHeightBiggerThanMax, height, IDASEN_MAX_HEIGHT)
 	}
 
-	if desiredHeight < float32(IDASEN_MIN_HEIGHT) {
-		return ErrHeightBiggerThanMax
+	if height < float32(IDASEN_MIN_HEIGHT) {
+		return fmt.Errorf("%w (%.2f m < %.2f m)", ErrHeightSmallerThanMin, height, IDASEN_MIN_HEIGHT)
+	}
+
+	return nil
+}
+
+func (c *Controller) MoveTo(ctx context.Context, desiredHeight float32, updates chan<- float32) error {
+	err := ValidateHeight(desiredHeight)
+	if err != nil {
+		return err
 	}
 
+	var lastHeight float32
+	lastChange := time.Now()
 	for {
 		select {
 		case <-ctx.Done():
+			c.stop()
 			return nil
 		default:
 		}
@@ -60,8 +82,21 @@ func (c *Controller) MoveTo(ctx context.Context, desiredHeight float32, updates
 			return err
 		}
 
+		if math.Abs(float64(currentHeight-lastHeight)) >= 0.001 {
+			lastHeight = currentHeight
+			lastChange = time.Now()
+		} else if time.Since(lastChange) > stallTimeout {
+			c.stop()
+			return fmt.Errorf("%w at %.2f m", ErrStalled, currentHeight)
+		}
+
 		if updates != nil {
-			updates <- currentHeight
+			select {
+			case updates <- currentHeight:
+			case <-ctx.Done():
+				c.stop()
+				return nil
+			}
 		}
 
 		if math.Abs(float64(desiredHeight-currentHeight)) < 0.005 {
@@ -92,8 +127,27 @@ func (c *Controller) GetCurrentHeight() (float32, error) {
 	if err != nil {
 		return 0, err
 	}
+	return decodeHeight(b), nil
+}
+
+// WatchHeight calls fn with the height every time the desk reports that it
+// changed, including moves made with the desk's own buttons.
+func (c *Controller) WatchHeight(fn func(height float32)) error {
+	return c.adaptor.Subscribe(IDASEN_UUID_HEIGHT, func(b []byte) {
+		if len(b) >= 2 {
+			fn(decodeHeight(b))
+		}
+	})
+}
+
+// Close disconnects from the desk.
+func (c *Controller) Close() error {
+	return c.adaptor.Disconnect()
+}
+
+func decodeHeight(b []byte) float32 {
 	raw := binary.LittleEndian.Uint16(b[0:2])
-	return float32(float32(raw)/10000) + float32(IDASEN_MIN_HEIGHT), err
+	return float32(float32(raw)/10000) + float32(IDASEN_MIN_HEIGHT)
 }
 
 func (c *Controller) moveUp() error {
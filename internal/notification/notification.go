@@ -1,9 +1,19 @@
 package notification
 
 import (
+	"errors"
+
 	"github.com/gen2brain/beeep"
 )
 
+var ErrActionsNotSupported = errors.New("notification server does not support actions")
+
+// Action is a button shown on a notification.
+type Action struct {
+	Key   string
+	Label string
+}
+
 type Notifier struct{}
 
 func NewNotifier() *Notifier {
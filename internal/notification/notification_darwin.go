@@ -0,0 +1,9 @@
+package notification
+
+// SendActionNotification sends a notification with buttons that call
+// onAction with the key of the clicked action. Notifications on macOS have
+// no buttons, so only the message is shown. dismiss removes the
+// notification.
+func (n *Notifier) SendActionNotification(title, message string, actions []Action, onAction func(key string)) (dismiss func(), err error) {
+	return func() {}, n.SendNotification(title, message)
+}
@@ -0,0 +1,81 @@
+package notification
+
+import (
+	"io"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/esiqveland/notify"
+	"github.com/godbus/dbus/v5"
+)
+
+// SendActionNotification sends a notification with buttons that call
+// onAction with the key of the clicked action. When the notification server
+// does not support buttons, only the message is shown. dismiss removes the
+// notification and must be called once the actions no longer apply.
+func (n *Notifier) SendActionNotification(title, message string, actions []Action, onAction func(key string)) (dismiss func(), err error) {
+	dismiss, err = sendDBusNotification(title, message, actions, onAction)
+	if err != nil {
+		return func() {}, n.SendNotification(title, message)
+	}
+	return dismiss, nil
+}
+
+func sendDBusNotification(title, message string, actions []Action, onAction func(key string)) (func(), error) {
+	// A private connection, so closing it does not affect other users of
+	// the session bus.
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	// The signals of notifications sent by other apps arrive as well.
+	var id atomic.Uint32
+	notifier, err := notify.New(conn,
+		notify.WithLogger(log.New(io.Discard, "", 0)),
+		notify.WithOnAction(func(signal *notify.ActionInvokedSignal) {
+			if signal.ID == id.Load() {
+				// Off the event loop, as the handler may dismiss the
+				// notification.
+				go onAction(signal.ActionKey)
+			}
+		}),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	capabilities, err := notifier.GetCapabilities()
+	if err != nil || !slices.Contains(capabilities, "actions") {
+		notifier.Close()
+		conn.Close()
+		return nil, ErrActionsNotSupported
+	}
+
+	note := notify.Notification{
+		AppName:       "idasenctl",
+		Summary:       title,
+		Body:          message,
+		ExpireTimeout: notify.ExpireTimeoutNever,
+	}
+	for _, action := range actions {
+		note.Actions = append(note.Actions, notify.Action{Key: action.Key, Label: action.Label})
+	}
+
+	sent, err := notifier.SendNotification(note)
+	if err != nil {
+		notifier.Close()
+		conn.Close()
+		return nil, err
+	}
+	id.Store(sent)
+
+	return sync.OnceFunc(func() {
+		notifier.CloseNotification(sent)
+		notifier.Close()
+		conn.Close()
+	}), nil
+}
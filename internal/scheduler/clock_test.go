@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only changes when a test says so. Like
+// the monotonic clock timers run on, its timers only count time that
+// passed with Advance, not wall clock changes made with Jump.
+type fakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	elapsed time.Duration
+	timers  []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock    *fakeClock
+	deadline time.Duration
+	c        chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	c := &fakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, deadline: c.elapsed + d, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+// remove drops a pending timer. The caller must hold mu.
+func (c *fakeClock) remove(t *fakeTimer) bool {
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance lets d pass, firing the timers that expire on the way at their
+// time.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.elapsed + d
+	for {
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if t.deadline <= end && (next == nil || t.deadline < next.deadline) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.now = c.now.Add(next.deadline - c.elapsed)
+		c.elapsed = next.deadline
+		c.remove(next)
+		next.c <- c.now
+		// Let the scheduler handle the timer before time moves on.
+		for len(c.timers) == 0 {
+			c.cond.Wait()
+		}
+	}
+	c.now = c.now.Add(end - c.elapsed)
+	c.elapsed = end
+}
+
+// Jump changes the wall clock by d without time passing for the timers, as
+// when the computer is suspended or the clock is set.
+func (c *fakeClock) Jump(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// WaitForTimer blocks until somebody waits on a timer that expires within
+// d.
+func (c *fakeClock) WaitForTimer(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for !c.pendingWithin(d) {
+			c.cond.Wait()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a timer within %s", d)
+	}
+}
+
+// pendingWithin reports whether a timer expires within d. The caller must
+// hold mu.
+func (c *fakeClock) pendingWithin(d time.Duration) bool {
+	for _, t := range c.timers {
+		if t.deadline <= c.elapsed+d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+// Conflict is a minute in which more than one schedule moves the same desk.
+type Conflict struct {
+	Desk      string
+	At        time.Time
+	Schedules []string
+}
+
+// FindConflicts returns the minutes within (from, until] in which more than
+// one of the enabled schedules moves the same desk, earliest first.
+func FindConflicts(schedules []config.Schedule, from time.Time, until time.Time) []Conflict {
+	byDesk := make(map[string][]config.Schedule)
+	for _, schedule := range schedules {
+		if !schedule.Enabled || !schedule.MovesDesk() {
+			continue
+		}
+		byDesk[schedule.DeskName] = append(byDesk[schedule.DeskName], schedule)
+	}
+
+	var conflicts []Conflict
+	for desk, deskSchedules := range byDesk {
+		if len(deskSchedules) > 1 {
+			conflicts = append(conflicts, deskConflicts(desk, deskSchedules, from, until)...)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if !conflicts[i].At.Equal(conflicts[j].At) {
+			return conflicts[i].At.Before(conflicts[j].At)
+		}
+		return conflicts[i].Desk < conflicts[j].Desk
+	})
+	return conflicts
+}
+
+// deskConflicts walks the runs of the schedules of one desk side by side,
+// minute by minute in which any of them runs, so there is no bound on how
+// many runs are compared.
+func deskConflicts(desk string, schedules []config.Schedule, from time.Time, until time.Time) []Conflict {
+	// next holds the minute of the next run of each schedule, zero once it
+	// has no more runs until until.
+	next := make([]time.Time, len(schedules))
+	advance := func(i int, after time.Time) {
+		event, err := NextEvent(schedules[i], after)
+		if err != nil || event.At.After(until) {
+			next[i] = time.Time{}
+			return
+		}
+		next[i] = event.At.Truncate(time.Minute)
+	}
+	for i := range schedules {
+		advance(i, from)
+	}
+
+	var conflicts []Conflict
+	for {
+		var minute time.Time
+		for _, at := range next {
+			if !at.IsZero() && (minute.IsZero() || at.Before(minute)) {
+				minute = at
+			}
+		}
+		if minute.IsZero() {
+			return conflicts
+		}
+
+		var names []string
+		for i, at := range next {
+			if !at.Equal(minute) {
+				continue
+			}
+			names = append(names, schedules[i].Name)
+			// More runs of the same schedule in this minute are no conflict.
+			advance(i, minute.Add(time.Minute-time.Nanosecond))
+		}
+		if len(names) > 1 {
+			conflicts = append(conflicts, Conflict{
+				Desk:      desk,
+				At:        minute,
+				Schedules: names,
+			})
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+func TestFindConflicts(t *testing.T) {
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	cron := func(name string, desk string, expr string) config.Schedule {
+		return config.Schedule{Name: name, DeskName: desk, Enabled: true, Cron: expr, PresetName: "stand", Timezone: "UTC"}
+	}
+
+	tests := []struct {
+		name      string
+		schedules []config.Schedule
+		until     time.Time
+		want      int
+		first     []string
+	}{
+		{
+			name:      "same minute",
+			schedules: []config.Schedule{cron("a", "desk", "0 9 * * *"), cron("b", "desk", "0 9 * * 1-5")},
+			until:     from.AddDate(0, 0, 7),
+			want:      5,
+			first:     []string{"a", "b"},
+		},
+		{
+			name:      "other desk",
+			schedules: []config.Schedule{cron("a", "desk", "0 9 * * *"), cron("b", "other", "0 9 * * *")},
+			until:     from.AddDate(0, 0, 7),
+		},
+		{
+			name: "disabled",
+			schedules: []config.Schedule{cron("a", "desk", "0 9 * * *"), func() config.Schedule {
+				s := cron("b", "desk", "0 9 * * *")
+				s.Enabled = false
+				return s
+			}()},
+			until: from.AddDate(0, 0, 7),
+		},
+		{
+			// Many more runs than the scheduler looks at after a downtime.
+			name:      "every minute",
+			schedules: []config.Schedule{cron("a", "desk", "* * * * *"), cron("b", "desk", "30 23 * * *")},
+			until:     from.AddDate(0, 0, 35),
+			want:      35,
+			first:     []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := FindConflicts(tt.schedules, from, tt.until)
+			if len(conflicts) != tt.want {
+				t.Fatalf("got %d conflicts, want %d", len(conflicts), tt.want)
+			}
+			if tt.want > 0 && !slices.Equal(conflicts[0].Schedules, tt.first) {
+				t.Errorf("first conflict between %q, want %q", conflicts[0].Schedules, tt.first)
+			}
+			if tt.want > 0 && !conflicts[len(conflicts)-1].At.After(conflicts[0].At) {
+				t.Errorf("conflicts are not in order")
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCron = errors.New("invalid cron expression")
+)
+
+// cronSearchYears bounds how far ahead Next looks for a matching time, so
+// expressions that never match, like February 30th, terminate.
+const cronSearchYears = 5
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Cron is a parsed five field cron expression: minute, hour, day of month,
+// month and day of week. Besides the usual lists, ranges and steps, the day
+// of week field accepts DAY#N for the Nth such weekday of the month, so
+// "0 9 * * mon#1" fires at 09:00 on the first Monday of every month. Days of
+// the week run from 0 to 7, both Sunday, and their ranges may wrap around
+// the end of the week, like fri-mon.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// nthDow holds DAY#N entries as N*7+DAY.
+	nthDow           []int
+	domStar, dowStar bool
+}
+
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields, got %d", ErrInvalidCron, expr, len(fields))
+	}
+
+	c := &Cron{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("%w %q: minute: %v", ErrInvalidCron, expr, err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("%w %q: hour: %v", ErrInvalidCron, expr, err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("%w %q: day of month: %v", ErrInvalidCron, expr, err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("%w %q: month: %v", ErrInvalidCron, expr, err)
+	}
+	if c.dow, c.nthDow, err = parseCronDow(fields[4]); err != nil {
+		return nil, fmt.Errorf("%w %q: day of week: %v", ErrInvalidCron, expr, err)
+	}
+
+	return c, nil
+}
+
+// Next returns the first matching time strictly after after, in the
+// location of after. Times skipped or repeated by daylight saving time
+// changes follow the rule of wallTime.
+func (c *Cron) Next(after time.Time) (time.Time, error) {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	year, month, day := t.Date()
+	hour, minute := t.Hour(), t.Minute()
+	limit := year + cronSearchYears
+
+	for year <= limit {
+		if c.month&(1<<uint(month)) == 0 {
+			year, month, day = time.Date(year, month+1, 1, 0, 0, 0, 0, loc).Date()
+			hour, minute = 0, 0
+			continue
+		}
+
+		date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if c.matchesDay(date) {
+			for h := hour; h < 24; h++ {
+				if c.hour&(1<<uint(h)) == 0 {
+					continue
+				}
+				startMinute := 0
+				if h == hour {
+					startMinute = minute
+				}
+				for m := startMinute; m < 60; m++ {
+					if c.minute&(1<<uint(m)) == 0 {
+						continue
+					}
+					candidate := wallTime(year, month, day, h, m, loc)
+					if candidate.After(after) {
+						return candidate, nil
+					}
+				}
+			}
+		}
+
+		year, month, day = date.AddDate(0, 0, 1).Date()
+		hour, minute = 0, 0
+	}
+
+	return time.Time{}, ErrNoNextRun
+}
+
+// matchesDay follows the classic cron rule: when both the day of month and
+// the day of week are restricted, a day matching either one matches.
+func (c *Cron) matchesDay(date time.Time) bool {
+	domMatch := c.dom&(1<<uint(date.Day())) != 0
+	dowMatch := c.dow&(1<<uint(date.Weekday())) != 0
+	for _, nth := range c.nthDow {
+		if int(date.Weekday()) == nth%7 && (date.Day()-1)/7+1 == nth/7 {
+			dowMatch = true
+		}
+	}
+
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dowMatch
+	case c.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+func parseCronDow(field string) (uint64, []int, error) {
+	var plain []string
+	var nth []int
+	var wrapped uint64
+	for _, part := range strings.Split(field, ",") {
+		day, n, found := strings.Cut(part, "#")
+		if !found {
+			bits, ok, err := parseWrappingDays(part)
+			if err != nil {
+				return 0, nil, err
+			}
+			if ok {
+				wrapped |= bits
+			} else {
+				plain = append(plain, part)
+			}
+			continue
+		}
+
+		d, err := parseCronValue(day, cronDayNames)
+		if err != nil || d < 0 || d > 7 {
+			return 0, nil, fmt.Errorf("invalid day %q", day)
+		}
+		k, err := strconv.Atoi(n)
+		if err != nil || k < 1 || k > 5 {
+			return 0, nil, fmt.Errorf("invalid occurrence %q, must be 1-5", n)
+		}
+		nth = append(nth, k*7+d%7)
+	}
+
+	bits := wrapped
+	if len(plain) > 0 {
+		var err error
+		plainBits, err := parseCronField(strings.Join(plain, ","), 0, 7, cronDayNames)
+		if err != nil {
+			return 0, nil, err
+		}
+		bits |= plainBits
+		// Both 0 and 7 mean Sunday.
+		if bits&(1<<7) != 0 {
+			bits |= 1
+		}
+	}
+
+	return bits, nth, nil
+}
+
+// parseWrappingDays parses a day of week range that wraps around the end of
+// the week, like fri-mon or sat-tue/2. It returns false for other parts.
+func parseWrappingDays(part string) (uint64, bool, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	from, to, isRange := strings.Cut(rangePart, "-")
+	if !isRange {
+		return 0, false, nil
+	}
+	start, err := parseCronValue(from, cronDayNames)
+	if err != nil {
+		return 0, false, nil
+	}
+	end, err := parseCronValue(to, cronDayNames)
+	if err != nil || start <= end || start > 7 || end < 0 {
+		return 0, false, nil
+	}
+
+	step := 1
+	if hasStep {
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step < 1 {
+			return 0, false, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+
+	var bits uint64
+	for v := start; v <= end+7; v += step {
+		bits |= 1 << uint(v%7)
+	}
+	return bits, true, nil
+}
+
+func parseCronField(field string, lo int, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		start, end := lo, hi
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(from, names); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(to, names); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangePart, names); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = hi
+			}
+		}
+
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
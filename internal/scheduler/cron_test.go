@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2026-10-19 is a Monday.
+	after := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"*/30 9-17 * * 1-5", []string{"2026-10-19 09:00", "2026-10-19 09:30", "2026-10-19 10:00"}},
+		{"*/45 9 * * *", []string{"2026-10-19 09:00", "2026-10-19 09:45", "2026-10-20 09:00"}},
+		{"0 9 * * mon#1", []string{"2026-11-02 09:00", "2026-12-07 09:00", "2027-01-04 09:00"}},
+		{"0 9 * * fri#5", []string{"2026-10-30 09:00", "2027-01-29 09:00", "2027-04-30 09:00"}},
+		{"0 10 * * 7", []string{"2026-10-25 10:00", "2026-11-01 10:00"}},
+		{"0 10 * * 0", []string{"2026-10-25 10:00", "2026-11-01 10:00"}},
+		{"0 10 * * fri-sun", []string{"2026-10-23 10:00", "2026-10-24 10:00", "2026-10-25 10:00", "2026-10-30 10:00"}},
+		{"0 10 * * sat-mon", []string{"2026-10-19 10:00", "2026-10-24 10:00", "2026-10-25 10:00", "2026-10-26 10:00"}},
+		{"0 10 * * 5-7", []string{"2026-10-23 10:00", "2026-10-24 10:00", "2026-10-25 10:00", "2026-10-30 10:00"}},
+		{"0 10 * * fri-tue/2", []string{"2026-10-20 10:00", "2026-10-23 10:00", "2026-10-25 10:00", "2026-10-27 10:00"}},
+		{"0 12 1,15 * *", []string{"2026-11-01 12:00", "2026-11-15 12:00"}},
+		{"0 12 13 * fri", []string{"2026-10-23 12:00", "2026-10-30 12:00", "2026-11-06 12:00", "2026-11-13 12:00"}},
+		{"0 0 29 feb *", []string{"2028-02-29 00:00"}},
+		{"@weekly", []string{"2026-10-25 00:00", "2026-11-01 00:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at := after
+			for _, want := range tt.want {
+				at, err = cron.Next(at)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := at.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("Next = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronNeverMatching(t *testing.T) {
+	cron, err := ParseCron("0 9 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cron.Next(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+	if !errors.Is(err, ErrNoNextRun) {
+		t.Errorf("Next error = %v, want %v", err, ErrNoNextRun)
+	}
+}
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"0 9 * * * *",
+		"60 9 * * *",
+		"0 24 * * *",
+		"0 9 0 * *",
+		"0 9 * 13 *",
+		"0 9 * * 8",
+		"0 17-9 * * *",
+		"*/0 9 * * *",
+		"0 9 * * mon#0",
+		"0 9 * * mon#6",
+		"0 9 * * funday",
+		"0 9 * * fri-mon/0",
+	} {
+		_, err := ParseCron(expr)
+		if !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) error = %v, want %v", expr, err, ErrInvalidCron)
+		}
+	}
+}
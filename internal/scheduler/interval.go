@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+var (
+	ErrInvalidInterval = errors.New("invalid interval schedule")
+)
+
+type intervalWindow struct {
+	interval   *config.IntervalSchedule
+	start, end time.Time
+	period     time.Duration
+}
+
+// ValidateInterval checks the durations and window of an interval schedule.
+func ValidateInterval(interval *config.IntervalSchedule) error {
+	if interval == nil {
+		return fmt.Errorf("%w: missing interval settings", ErrInvalidInterval)
+	}
+	if interval.FirstPreset == "" || interval.SecondPreset == "" {
+		return fmt.Errorf("%w: two presets are required", ErrInvalidInterval)
+	}
+	if interval.FirstDuration < time.Minute || interval.SecondDuration < time.Minute {
+		return fmt.Errorf("%w: durations must be at least one minute", ErrInvalidInterval)
+	}
+
+	start, err := time.Parse("15:04", interval.Start)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start %q", ErrInvalidInterval, interval.Start)
+	}
+	end, err := time.Parse("15:04", interval.End)
+	if err != nil {
+		return fmt.Errorf("%w: invalid end %q", ErrInvalidInterval, interval.End)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("%w: window end %s must be after its start %s", ErrInvalidInterval, interval.End, interval.Start)
+	}
+
+	return nil
+}
+
+// windowOn returns the window of the schedule on the day of date.
+func windowOn(schedule config.Schedule, date time.Time) (intervalWindow, error) {
+	interval := schedule.Interval
+	err := ValidateInterval(interval)
+	if err != nil {
+		return intervalWindow{}, err
+	}
+
+	start, _ := time.Parse("15:04", interval.Start)
+	end, _ := time.Parse("15:04", interval.End)
+	year, month, day := date.Date()
+
+	return intervalWindow{
+		interval: interval,
+		start:    wallTime(year, month, day, start.Hour(), start.Minute(), date.Location()),
+		end:      wallTime(year, month, day, end.Hour(), end.Minute(), date.Location()),
+		period:   interval.FirstDuration + interval.SecondDuration,
+	}, nil
+}
+
+// phaseAt returns the preset, start and end of the phase containing t, which
+// must be inside the window.
+func (w intervalWindow) phaseAt(t time.Time) (string, time.Time, time.Time) {
+	cycleStart := w.start.Add(t.Sub(w.start) / w.period * w.period)
+	secondStart := cycleStart.Add(w.interval.FirstDuration)
+	if t.Before(secondStart) {
+		return w.interval.FirstPreset, cycleStart, secondStart
+	}
+	return w.interval.SecondPreset, secondStart, cycleStart.Add(w.period)
+}
+
+// nextInterval returns the first phase change strictly after after.
+func nextInterval(schedule config.Schedule, after time.Time) (time.Time, error) {
+	year, month, day := after.Date()
+	for i := 0; i <= 7; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, after.Location())
+		if !runsOn(schedule, date.Weekday()) {
+			continue
+		}
+
+		w, err := windowOn(schedule, date)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if w.start.After(after) {
+			return w.start, nil
+		}
+		if !after.Before(w.end) {
+			continue
+		}
+
+		_, _, next := w.phaseAt(after)
+		if next.Before(w.end) {
+			return next, nil
+		}
+	}
+
+	return time.Time{}, ErrNoNextRun
+}
+
+// IntervalPhase returns the preset an interval schedule has the desk in at
+// t and when that phase started, in the location of t. ok is false outside
+// of the daily window in the time zone of the schedule.
+func IntervalPhase(schedule config.Schedule, t time.Time) (presetName string, since time.Time, ok bool) {
+	resultLoc := t.Location()
+	t = inLocation(schedule, t)
+	if schedule.Kind != config.KindInterval || !runsOn(schedule, t.Weekday()) || !onPlanDay(schedule, t) {
+		return "", time.Time{}, false
+	}
+
+	w, err := windowOn(schedule, t)
+	if err != nil || t.Before(w.start) || !t.Before(w.end) {
+		return "", time.Time{}, false
+	}
+
+	presetName, since, _ = w.phaseAt(t)
+	return presetName, since.In(resultLoc), true
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+const (
+	// MaxJitter bounds the jitter of a schedule.
+	MaxJitter = time.Hour
+
+	// maxJitterRuns bounds how many runs Next looks at to find the first one
+	// after jitter.
+	maxJitterRuns = 1000
+)
+
+// jitterSeed makes the offsets differ between computers, so desks sharing
+// a schedule do not all move at the same time.
+var jitterSeed = sync.OnceValue(func() string {
+	hostname, _ := os.Hostname()
+	return hostname
+})
+
+// jitterOffset returns how much the run of the schedule at scheduled is
+// moved, a random offset within the jitter, to the second. It is the same
+// every time it is computed, so the daemon and the commands listing
+// upcoming moves agree.
+func jitterOffset(schedule config.Schedule, scheduled time.Time) time.Duration {
+	jitter := int64(schedule.Jitter / time.Second)
+	if jitter <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%d", jitterSeed(), schedule.Name, scheduled.Unix())
+	return time.Duration(int64(h.Sum64()%uint64(2*jitter+1))-jitter) * time.Second
+}
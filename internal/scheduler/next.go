@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+var (
+	ErrNoNextRun = errors.New("schedule has no upcoming run")
+)
+
+// Event is a single run of a schedule.
+type Event struct {
+	Schedule   config.Schedule
+	At         time.Time
+	PresetName string
+}
+
+// Describe describes what the event does, such as "move to preset 'stand'".
+func (e Event) Describe() string {
+	return e.Schedule.DescribeAction(e.PresetName)
+}
+
+// NextEvent returns the first run of the schedule strictly after after,
+// along with the preset it moves the desk to.
+func NextEvent(schedule config.Schedule, after time.Time) (Event, error) {
+	at, err := Next(schedule, after)
+	if err != nil {
+		return Event{}, err
+	}
+
+	presetName := schedule.PresetName
+	if schedule.Kind == config.KindInterval {
+		presetName, _, _ = IntervalPhase(schedule, at)
+	}
+
+	return Event{
+		Schedule:   schedule,
+		At:         at,
+		PresetName: presetName,
+	}, nil
+}
+
+// maxSkips bounds how many excluded days, or days its plan is not in use,
+// Next skips before it gives up.
+const maxSkips = 1000
+
+// Next returns the first time strictly after after at which the schedule
+// fires, in the location of after. The times of the schedule are in its
+// time zone, and runs on excluded days or on days its plan is not in use
+// are left out. With a jitter every run is moved by its jitter offset.
+func Next(schedule config.Schedule, after time.Time) (time.Time, error) {
+	if schedule.Jitter <= 0 || schedule.Kind != config.KindFixed {
+		return nextScheduled(schedule, after)
+	}
+
+	// Runs scheduled up to the jitter before after may be moved past it,
+	// and with short gaps between runs their order may change.
+	var next time.Time
+	scheduledAfter := after.Add(-schedule.Jitter)
+	for range maxJitterRuns {
+		scheduled, err := nextScheduled(schedule, scheduledAfter)
+		if errors.Is(err, ErrNoNextRun) {
+			break
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !next.IsZero() && !scheduled.Add(-schedule.Jitter).Before(next) {
+			break
+		}
+
+		at := scheduled.Add(jitterOffset(schedule, scheduled))
+		if at.After(after) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+		scheduledAfter = scheduled
+	}
+
+	if next.IsZero() {
+		return time.Time{}, ErrNoNextRun
+	}
+	return next, nil
+}
+
+// nextScheduled returns the first time strictly after after at which the
+// schedule is scheduled to fire, before any jitter.
+func nextScheduled(schedule config.Schedule, after time.Time) (time.Time, error) {
+	loc, err := schedule.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	resultLoc := after.Location()
+	after = after.In(loc)
+
+	for range maxSkips {
+		at, err := nextRun(schedule, after)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		lastDay := at.Format(config.DateFormat)
+		if exclusion, excluded := Excluded(schedule, at); excluded {
+			lastDay = exclusion.LastDay()
+		} else if onPlanDay(schedule, at) {
+			return at.In(resultLoc), nil
+		}
+
+		// Continue with the runs after the last skipped day.
+		day, err := time.ParseInLocation(config.DateFormat, lastDay, at.Location())
+		if err != nil {
+			return time.Time{}, err
+		}
+		after = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return time.Time{}, ErrNoNextRun
+}
+
+// Excluded returns the exclusion of the schedule that covers the day of t in
+// the time zone of the schedule. One-off schedules are never excluded.
+func Excluded(schedule config.Schedule, t time.Time) (config.Exclusion, bool) {
+	if schedule.Kind == config.KindOnce {
+		return config.Exclusion{}, false
+	}
+	t = inLocation(schedule, t)
+	for _, exclusion := range schedule.Exclusions {
+		if exclusion.Covers(t) {
+			return exclusion, true
+		}
+	}
+	return config.Exclusion{}, false
+}
+
+// onPlanDay reports whether the plan of the schedule is in use on the day of
+// t in the time zone of the schedule.
+func onPlanDay(schedule config.Schedule, t time.Time) bool {
+	return schedule.PlanDays == nil || slices.Contains(schedule.PlanDays, int(inLocation(schedule, t).Weekday()))
+}
+
+func nextRun(schedule config.Schedule, after time.Time) (time.Time, error) {
+	switch schedule.Kind {
+	case config.KindInterval:
+		return nextInterval(schedule, after)
+	case config.KindOnce:
+		if !schedule.At.After(after) {
+			return time.Time{}, ErrNoNextRun
+		}
+		return schedule.At.In(after.Location()), nil
+	}
+
+	if schedule.Cron != "" {
+		cron, err := ParseCron(schedule.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.Next(after)
+	}
+
+	clock, err := time.Parse("15:04", schedule.Time)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", schedule.Time, err)
+	}
+
+	year, month, day := after.Date()
+	for i := 0; i <= 7; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, time.UTC)
+		if !runsOn(schedule, date.Weekday()) {
+			continue
+		}
+		candidate := wallTime(year, month, day+i, clock.Hour(), clock.Minute(), after.Location())
+		if candidate.After(after) {
+			return candidate, nil
+		}
+	}
+
+	return time.Time{}, ErrNoNextRun
+}
+
+func runsOn(schedule config.Schedule, weekday time.Weekday) bool {
+	for _, day := range schedule.Days {
+		if day == int(weekday) {
+			return true
+		}
+	}
+	return false
+}
+
+// Upcoming returns the next count runs of the enabled schedules after
+// after, earliest first.
+func Upcoming(schedules []config.Schedule, after time.Time, count int) []Event {
+	var next []Event
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+		if event, err := NextEvent(schedule, after); err == nil {
+			next = append(next, event)
+		}
+	}
+
+	var events []Event
+	for len(events) < count && len(next) > 0 {
+		earliest := 0
+		for i, event := range next {
+			if event.At.Before(next[earliest].At) {
+				earliest = i
+			}
+		}
+
+		event := next[earliest]
+		events = append(events, event)
+
+		following, err := NextEvent(event.Schedule, event.At)
+		if err != nil {
+			next = append(next[:earliest], next[earliest+1:]...)
+			continue
+		}
+		next[earliest] = following
+	}
+	return events
+}
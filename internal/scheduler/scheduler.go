@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+const (
+	// maxSleep bounds how long the scheduler sleeps at once, so changes of
+	// the wall clock are noticed even when the next event is far away.
+	maxSleep = time.Minute
+
+	// jumpThreshold is how much later than planned the scheduler may wake up
+	// before it assumes the computer was suspended or the clock was changed.
+	jumpThreshold = 30 * time.Second
+
+	// lateTolerance is how late a run may be after a clock jump and still be
+	// handled as on time rather than missed.
+	lateTolerance = time.Minute
+
+	// maxDueEvents bounds how many past runs of a schedule are looked at
+	// after a long downtime.
+	maxDueEvents = 10000
+)
+
+// Handlers are called by the scheduler as events become due.
+type Handlers struct {
+	// Notify is called the schedule's countdown ahead of an event.
+	Notify func(event Event)
+	// Execute is called when an event is due.
+	Execute func(event Event)
+	// Missed is called for a missed event of a schedule with MissedNotify.
+	Missed func(event Event)
+	// Skipped is called for a missed event that is neither run nor notified.
+	Skipped func(event Event, reason string)
+	// Checked is called with the time up to which events were handled.
+	Checked func(now time.Time)
+}
+
+// Scheduler sleeps until the nearest notification or run of the enabled
+// schedules and calls its handlers for every event that became due.
+type Scheduler struct {
+	clock     Clock
+	schedules func() []config.Schedule
+	handlers  Handlers
+	wake      chan struct{}
+}
+
+func New(clock Clock, schedules func() []config.Schedule, handlers Handlers) *Scheduler {
+	return &Scheduler{
+		clock:     clock,
+		schedules: schedules,
+		handlers:  handlers,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Wake makes the scheduler recompute its next event, for example after the
+// schedules changed.
+func (s *Scheduler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run blocks until ctx is done. Events are due when their time falls within
+// the period since the previous check, so none is handled twice. When since
+// is set, events between since and now are handled first as missed ones.
+func (s *Scheduler) Run(ctx context.Context, since time.Time) {
+	last := s.now()
+	if !since.IsZero() && since.Before(last) {
+		log.Printf("Looking for runs missed since %s", since.Format(time.DateTime))
+		s.fire(since, last, last.Add(-lateTolerance))
+		s.checked(last)
+	}
+
+	// behind is set while the clock is behind last after it moved back.
+	behind := false
+	for {
+		sleep := maxSleep
+		if next, ok := s.nextEvent(last); ok {
+			sleep = min(max(next.Sub(s.now()), 0), maxSleep)
+		}
+		wakeAt := s.now().Add(sleep)
+
+		timer := s.clock.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C():
+		}
+
+		// Events up to last were handled, so they do not run again until
+		// the clock catches up.
+		now := s.now()
+		if now.Before(last) {
+			if !behind {
+				log.Printf("Clock moved back by %s, not running schedules again", last.Sub(now).Round(time.Second))
+				behind = true
+			}
+			continue
+		}
+		behind = false
+
+		var missedBefore time.Time
+		if late := now.Sub(wakeAt); late > jumpThreshold {
+			log.Printf("Clock jumped forward by %s, the computer was probably suspended", late.Round(time.Second))
+			missedBefore = now.Add(-lateTolerance)
+		}
+
+		s.fire(last, now, missedBefore)
+		s.checked(now)
+		last = now
+	}
+}
+
+// now returns the wall clock time. Times with a monotonic clock reading are
+// compared with it, but the monotonic clock stops while the computer is
+// suspended and does not follow changes of the wall clock, which are the
+// jumps the scheduler looks for.
+func (s *Scheduler) now() time.Time {
+	return s.clock.Now().Round(0)
+}
+
+// nextEvent returns the earliest notification or run after last.
+func (s *Scheduler) nextEvent(last time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+
+	for _, schedule := range s.schedules() {
+		if !schedule.Enabled {
+			continue
+		}
+
+		runAt, err := Next(schedule, last)
+		if err != nil {
+			continue
+		}
+
+		event := runAt
+		if notifyAt := runAt.Add(-schedule.CountdownDuration()); notifyAt.After(last) {
+			event = notifyAt
+		}
+
+		if !found || event.Before(next) {
+			next = event
+			found = true
+		}
+	}
+
+	return next, found
+}
+
+// fire handles the events that fall within (last, now]. Runs at or before
+// missedBefore were missed and are handled by the missed policy of their
+// schedule.
+func (s *Scheduler) fire(last time.Time, now time.Time, missedBefore time.Time) {
+	for _, schedule := range s.schedules() {
+		if !schedule.Enabled {
+			continue
+		}
+
+		upcoming, err := NextEvent(schedule, now)
+		if err != nil && !errors.Is(err, ErrNoNextRun) {
+			log.Printf("Error computing next run for schedule %s: %v", schedule.Name, err)
+			continue
+		}
+		if err == nil {
+			if notifyAt := upcoming.At.Add(-schedule.CountdownDuration()); notifyAt.After(last) && !notifyAt.After(now) {
+				s.handlers.Notify(upcoming)
+			}
+		}
+
+		due := dueEvents(schedule, last, now)
+		if len(due) == 0 {
+			continue
+		}
+		latest := due[len(due)-1]
+
+		if missedBefore.IsZero() || latest.At.After(missedBefore) {
+			if len(due) > 1 {
+				log.Printf("Skipping %d earlier runs of schedule %s superseded by the run at %s",
+					len(due)-1, schedule.Name, latest.At.Format("15:04"))
+			}
+			s.handlers.Execute(latest)
+			continue
+		}
+
+		s.handleMissed(schedule, due, now)
+	}
+}
+
+func (s *Scheduler) handleMissed(schedule config.Schedule, due []Event, now time.Time) {
+	latest := due[len(due)-1]
+	late := now.Sub(latest.At).Round(time.Second)
+	if len(due) > 1 {
+		log.Printf("Schedule %s missed %d runs, only the latest at %s is considered",
+			schedule.Name, len(due), latest.At.Format(time.DateTime))
+	}
+
+	switch schedule.MissedPolicy {
+	case config.MissedRunLatest:
+		grace := schedule.GracePeriod
+		if grace <= 0 {
+			grace = config.DefaultGracePeriod
+		}
+		if now.Sub(latest.At) > grace {
+			log.Printf("Skipping missed run of schedule %s at %s: %s late, beyond the grace period of %s",
+				schedule.Name, latest.At.Format(time.DateTime), late, grace)
+			s.skipped(latest, fmt.Sprintf("missed by %s, beyond the grace period of %s", late, grace))
+			return
+		}
+		log.Printf("Running missed run of schedule %s at %s: %s late, within the grace period of %s",
+			schedule.Name, latest.At.Format(time.DateTime), late, grace)
+		s.handlers.Execute(latest)
+	case config.MissedNotify:
+		log.Printf("Missed run of schedule %s at %s (%s late), notifying only",
+			schedule.Name, latest.At.Format(time.DateTime), late)
+		s.handlers.Missed(latest)
+	default:
+		log.Printf("Skipping missed run of schedule %s at %s (%s late)",
+			schedule.Name, latest.At.Format(time.DateTime), late)
+		s.skipped(latest, fmt.Sprintf("missed by %s", late))
+	}
+}
+
+func (s *Scheduler) skipped(event Event, reason string) {
+	if s.handlers.Skipped != nil {
+		s.handlers.Skipped(event, reason)
+	}
+}
+
+func (s *Scheduler) checked(now time.Time) {
+	if s.handlers.Checked != nil {
+		s.handlers.Checked(now)
+	}
+}
+
+// dueEvents returns the runs of the schedule within (last, now], oldest first.
+func dueEvents(schedule config.Schedule, last time.Time, now time.Time) []Event {
+	var due []Event
+	after := last
+	for len(due) < maxDueEvents {
+		event, err := NextEvent(schedule, after)
+		if err != nil || event.At.After(now) {
+			break
+		}
+		due = append(due, event)
+		after = event.At
+	}
+	return due
+}
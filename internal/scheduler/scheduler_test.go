@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+// call is a call of a handler of the scheduler.
+type call struct {
+	handler  string
+	schedule string
+	at       time.Time // Time of the event
+	now      time.Time // Time of the clock when it was handled
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	clock *fakeClock
+	calls []call
+}
+
+func (r *recorder) handle(handler string) func(event Event) {
+	return func(event Event) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, call{handler, event.Schedule.Name, event.At, r.clock.Now()})
+	}
+}
+
+func (r *recorder) get() []call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]call(nil), r.calls...)
+}
+
+// schedules holds the schedules of a test scheduler, which may change while
+// it runs.
+type schedules struct {
+	mu        sync.Mutex
+	schedules []config.Schedule
+}
+
+func (s *schedules) get() []config.Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.schedules
+}
+
+func (s *schedules) set(schedules ...config.Schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules = schedules
+}
+
+// startScheduler runs a scheduler until the test ends and waits until it
+// sleeps.
+func startScheduler(t *testing.T, clock *fakeClock, list *schedules, since time.Time) (*Scheduler, *recorder) {
+	t.Helper()
+
+	rec := &recorder{clock: clock}
+	s := New(clock, list.get, Handlers{
+		Notify:  rec.handle("notify"),
+		Execute: rec.handle("execute"),
+		Missed:  rec.handle("missed"),
+		Skipped: func(event Event, reason string) { rec.handle("skipped")(event) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, since)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	clock.WaitForTimer(t, maxSleep)
+	return s, rec
+}
+
+func dailySchedule(name, clock string) config.Schedule {
+	return config.Schedule{
+		Name:       name,
+		Kind:       config.KindFixed,
+		Time:       clock,
+		DeskName:   "desk",
+		PresetName: "stand",
+		Enabled:    true,
+		Days:       []int{0, 1, 2, 3, 4, 5, 6},
+		Timezone:   "UTC",
+	}
+}
+
+func at(clock string) time.Time {
+	t, err := time.Parse(time.DateTime, "2026-10-19 "+clock)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func assertCalls(t *testing.T, got []call, want []call) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d calls %v, want %d calls %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i].handler != want[i].handler || got[i].schedule != want[i].schedule ||
+			!got[i].at.Equal(want[i].at) || !got[i].now.Equal(want[i].now) {
+			t.Errorf("call %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSchedulerRunsAtEventTime(t *testing.T) {
+	clock := newFakeClock(at("08:00:00"))
+	_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{dailySchedule("stand", "09:00")}}, time.Time{})
+
+	clock.Advance(2 * time.Hour)
+
+	assertCalls(t, rec.get(), []call{
+		{"notify", "stand", at("09:00:00"), at("08:59:50")},
+		{"execute", "stand", at("09:00:00"), at("09:00:00")},
+	})
+}
+
+func TestSchedulerNotifiesCountdownAhead(t *testing.T) {
+	tests := []struct {
+		countdown time.Duration
+		notifyAt  time.Time
+	}{
+		{0, at("08:59:50")},
+		{2 * time.Minute, at("08:58:00")},
+		{90 * time.Minute, at("07:30:00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.countdown.String(), func(t *testing.T) {
+			schedule := dailySchedule("stand", "09:00")
+			schedule.Countdown = tt.countdown
+
+			clock := newFakeClock(at("07:00:00"))
+			_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{schedule}}, time.Time{})
+
+			clock.Advance(3 * time.Hour)
+
+			assertCalls(t, rec.get(), []call{
+				{"notify", "stand", at("09:00:00"), tt.notifyAt},
+				{"execute", "stand", at("09:00:00"), at("09:00:00")},
+			})
+		})
+	}
+}
+
+func TestSchedulerRunsFirstEventRightAfterStart(t *testing.T) {
+	clock := newFakeClock(at("08:59:59"))
+	_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{dailySchedule("stand", "09:00")}}, time.Time{})
+
+	clock.Advance(time.Second)
+
+	// The notification was due before the start.
+	assertCalls(t, rec.get(), []call{
+		{"execute", "stand", at("09:00:00"), at("09:00:00")},
+	})
+}
+
+func TestSchedulerWakesOnReload(t *testing.T) {
+	list := &schedules{}
+	clock := newFakeClock(at("08:58:30"))
+	s, rec := startScheduler(t, clock, list, time.Time{})
+
+	// Without schedules it sleeps maxSleep, past the notification at 08:59.
+	schedule := dailySchedule("stand", "09:00")
+	schedule.Countdown = time.Minute
+	list.set(schedule)
+	s.Wake()
+	clock.WaitForTimer(t, 30*time.Second)
+
+	clock.Advance(2 * time.Minute)
+
+	assertCalls(t, rec.get(), []call{
+		{"notify", "stand", at("09:00:00"), at("08:59:00")},
+		{"execute", "stand", at("09:00:00"), at("09:00:00")},
+	})
+}
+
+func TestSchedulerClockJumpsForward(t *testing.T) {
+	clock := newFakeClock(at("13:00:00"))
+	_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{dailySchedule("afternoon", "14:00")}}, time.Time{})
+
+	// The clock is set forward past the run while the scheduler sleeps.
+	clock.Jump(2*time.Hour + 51*time.Minute)
+	clock.Advance(time.Minute)
+
+	assertCalls(t, rec.get(), []call{
+		{"skipped", "afternoon", at("14:00:00"), at("15:52:00")},
+	})
+}
+
+func TestSchedulerHandlesRunsMissedWhileSuspended(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy config.MissedPolicy
+		grace  time.Duration
+		want   string
+	}{
+		{"skip", config.MissedSkip, 0, "skipped"},
+		{"latest beyond grace", config.MissedRunLatest, 0, "skipped"},
+		{"latest within grace", config.MissedRunLatest, 3 * time.Hour, "execute"},
+		{"notify", config.MissedNotify, 0, "missed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := dailySchedule("afternoon", "14:00")
+			schedule.MissedPolicy = tt.policy
+			schedule.GracePeriod = tt.grace
+
+			clock := newFakeClock(at("13:00:00"))
+			_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{schedule}}, time.Time{})
+
+			// Suspended from 13:00 to 15:51, the timer only counts the
+			// minute after the resume.
+			clock.Jump(2*time.Hour + 51*time.Minute)
+			clock.Advance(time.Minute)
+
+			assertCalls(t, rec.get(), []call{
+				{tt.want, "afternoon", at("14:00:00"), at("15:52:00")},
+			})
+		})
+	}
+}
+
+func TestSchedulerClockMovesBack(t *testing.T) {
+	clock := newFakeClock(at("08:59:00"))
+	_, rec := startScheduler(t, clock, &schedules{schedules: []config.Schedule{dailySchedule("stand", "09:00")}}, time.Time{})
+
+	clock.Advance(2 * time.Minute)
+	clock.Jump(-time.Hour)
+	clock.Advance(70 * time.Minute)
+
+	// The run at 09:00 is not repeated when the clock shows 09:00 again.
+	assertCalls(t, rec.get(), []call{
+		{"notify", "stand", at("09:00:00"), at("08:59:50")},
+		{"execute", "stand", at("09:00:00"), at("09:00:00")},
+	})
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+// inLocation returns t in the time zone of the schedule. Schedules with an
+// invalid time zone, which Validate rejects, keep the location of t.
+func inLocation(schedule config.Schedule, t time.Time) time.Time {
+	loc, err := schedule.Location()
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// wallTime returns the instant at which the clocks in loc show the given
+// day and time of day. Around daylight saving time changes it follows one
+// rule:
+//
+//   - A time skipped when the clocks go forward, such as 02:30 when they
+//     jump from 02:00 to 03:00, is the moment of the change, 03:00.
+//   - A time that occurs twice when the clocks go back, such as 01:30 when
+//     they fall from 02:00 to 01:00, is its first occurrence.
+//
+// So every scheduled time of day runs exactly once a day.
+func wallTime(year int, month time.Month, day, hour, minute int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+
+	// A change is never within a day of another, so the offsets a day
+	// before and after are the only ones that can apply.
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+
+	var first time.Time
+	for _, offset := range []int{before, after} {
+		t := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if sameWall(t, wall) && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	if !first.IsZero() {
+		return first
+	}
+
+	// The time was skipped. The change lies between the instant it would
+	// be at with the later offset and the one with the earlier offset.
+	lo := wall.Add(-time.Duration(after) * time.Second)
+	hi := wall.Add(-time.Duration(before) * time.Second)
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+		if _, offset := mid.In(loc).Zone(); offset == after {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi.In(loc)
+}
+
+func sameWall(t, wall time.Time) bool {
+	return t.Year() == wall.Year() && t.Month() == wall.Month() && t.Day() == wall.Day() &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute()
+}
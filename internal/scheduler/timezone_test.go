@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+// In America/New_York the clocks go forward from 02:00 to 03:00 on
+// 2026-03-08 and back from 02:00 to 01:00 on 2026-11-01.
+const newYork = "America/New_York"
+
+func mustParse(t *testing.T, s string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func everyDay(schedule config.Schedule) config.Schedule {
+	schedule.Name = "test"
+	schedule.DeskName = "desk"
+	schedule.Enabled = true
+	schedule.Days = []int{0, 1, 2, 3, 4, 5, 6}
+	schedule.Timezone = newYork
+	return schedule
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	atTime := func(clock string) config.Schedule {
+		return everyDay(config.Schedule{Kind: config.KindFixed, Time: clock, PresetName: "stand"})
+	}
+	cron := func(expr string) config.Schedule {
+		return everyDay(config.Schedule{Kind: config.KindFixed, Cron: expr, PresetName: "stand"})
+	}
+	interval := everyDay(config.Schedule{
+		Kind: config.KindInterval,
+		Interval: &config.IntervalSchedule{
+			FirstPreset:    "sit",
+			FirstDuration:  30 * time.Minute,
+			SecondPreset:   "stand",
+			SecondDuration: 30 * time.Minute,
+			Start:          "01:00",
+			End:            "04:00",
+		},
+	})
+
+	tests := []struct {
+		name     string
+		schedule config.Schedule
+		after    string
+		want     string
+	}{
+		// A time skipped when the clocks go forward runs at the change.
+		{"time in gap", atTime("02:30"), "2026-03-07T12:00:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"time in gap day after", atTime("02:30"), "2026-03-08T03:00:00-04:00", "2026-03-09T02:30:00-04:00"},
+		{"time after gap", atTime("03:00"), "2026-03-07T12:00:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"time before gap", atTime("01:59"), "2026-03-07T12:00:00-05:00", "2026-03-08T01:59:00-05:00"},
+		// A time that occurs twice when the clocks go back runs once, at
+		// its first occurrence.
+		{"time in overlap", atTime("01:30"), "2026-10-31T12:00:00-04:00", "2026-11-01T01:30:00-04:00"},
+		{"time in overlap once", atTime("01:30"), "2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		{"time after overlap", atTime("02:00"), "2026-10-31T12:00:00-04:00", "2026-11-01T02:00:00-05:00"},
+
+		{"cron in gap", cron("30 2 * * *"), "2026-03-07T12:00:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"cron steps into gap", cron("*/30 * * * *"), "2026-03-08T01:30:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"cron steps out of gap", cron("*/30 * * * *"), "2026-03-08T03:00:00-04:00", "2026-03-08T03:30:00-04:00"},
+		{"cron in overlap", cron("30 1 * * *"), "2026-10-31T12:00:00-04:00", "2026-11-01T01:30:00-04:00"},
+		{"cron in overlap once", cron("30 1 * * *"), "2026-11-01T01:30:00-04:00", "2026-11-02T01:30:00-05:00"},
+		{"cron steps over overlap", cron("*/30 * * * *"), "2026-11-01T01:30:00-04:00", "2026-11-01T02:00:00-05:00"},
+		{"cron during repeated hour", cron("*/30 * * * *"), "2026-11-01T01:10:00-05:00", "2026-11-01T02:00:00-05:00"},
+
+		// Phases last their durations in real time, so the window is an
+		// hour shorter or longer.
+		{"interval before gap", interval, "2026-03-08T01:00:00-05:00", "2026-03-08T01:30:00-05:00"},
+		{"interval across gap", interval, "2026-03-08T01:30:00-05:00", "2026-03-08T03:00:00-04:00"},
+		{"interval ends after gap", interval, "2026-03-08T03:30:00-04:00", "2026-03-09T01:00:00-04:00"},
+		{"interval starts in overlap", interval, "2026-10-31T12:00:00-04:00", "2026-11-01T01:00:00-04:00"},
+		{"interval across overlap", interval, "2026-11-01T01:30:00-04:00", "2026-11-01T01:00:00-05:00"},
+		{"interval ends after overlap", interval, "2026-11-01T03:30:00-05:00", "2026-11-02T01:00:00-05:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Next(tt.schedule, mustParse(t, tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParse(t, tt.want); !got.Equal(want) {
+				t.Errorf("Next after %s = %s, want %s", tt.after, got.Format(time.RFC3339), want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestIntervalPhaseAcrossDST(t *testing.T) {
+	schedule := everyDay(config.Schedule{
+		Kind: config.KindInterval,
+		Interval: &config.IntervalSchedule{
+			FirstPreset:    "sit",
+			FirstDuration:  45 * time.Minute,
+			SecondPreset:   "stand",
+			SecondDuration: 15 * time.Minute,
+			Start:          "01:00",
+			End:            "04:00",
+		},
+	})
+
+	tests := []struct {
+		at     string
+		preset string
+		since  string
+		ok     bool
+	}{
+		{"2026-03-08T00:59:00-05:00", "", "", false},
+		{"2026-03-08T01:50:00-05:00", "stand", "2026-03-08T01:45:00-05:00", true},
+		{"2026-03-08T03:10:00-04:00", "sit", "2026-03-08T03:00:00-04:00", true},
+		{"2026-03-08T04:00:00-04:00", "", "", false},
+		{"2026-11-01T01:20:00-04:00", "sit", "2026-11-01T01:00:00-04:00", true},
+		{"2026-11-01T01:20:00-05:00", "sit", "2026-11-01T01:00:00-05:00", true},
+		{"2026-11-01T03:59:00-05:00", "stand", "2026-11-01T03:45:00-05:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.at, func(t *testing.T) {
+			preset, since, ok := IntervalPhase(schedule, mustParse(t, tt.at))
+			if ok != tt.ok || preset != tt.preset {
+				t.Fatalf("IntervalPhase(%s) = %q, %v, want %q, %v", tt.at, preset, ok, tt.preset, tt.ok)
+			}
+			if ok && !since.Equal(mustParse(t, tt.since)) {
+				t.Errorf("IntervalPhase(%s) since %s, want %s", tt.at, since.Format(time.RFC3339), tt.since)
+			}
+		})
+	}
+}
+
+func TestNextUsesScheduleOrDefaultTimezone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`timezone: America/New_York
+schedules:
+  - name: default
+    time: "01:30"
+    deskName: desk
+    presetName: stand
+    enabled: true
+    days: [0, 1, 2, 3, 4, 5, 6]
+  - name: lisbon
+    time: "01:30"
+    deskName: desk
+    presetName: stand
+    enabled: true
+    days: [0, 1, 2, 3, 4, 5, 6]
+    timezone: Europe/Lisbon
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm, err := config.NewConfigManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lisbon goes back from 02:00 to 01:00 on 2026-10-25, a week before New
+	// York.
+	tests := []struct {
+		schedule string
+		after    string
+		want     string
+	}{
+		{"default", "2026-10-24T12:00:00Z", "2026-10-25T01:30:00-04:00"},
+		{"default", "2026-10-31T12:00:00Z", "2026-11-01T01:30:00-04:00"},
+		{"default", "2026-11-01T05:30:00Z", "2026-11-02T01:30:00-05:00"},
+		{"lisbon", "2026-10-24T12:00:00Z", "2026-10-25T01:30:00+01:00"},
+		{"lisbon", "2026-10-25T00:30:00Z", "2026-10-26T01:30:00Z"},
+		{"lisbon", "2026-10-31T12:00:00Z", "2026-11-01T01:30:00Z"},
+	}
+
+	schedules := make(map[string]config.Schedule)
+	for _, schedule := range cm.GetEffectiveSchedules() {
+		schedules[schedule.Name] = schedule
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule+" after "+tt.after, func(t *testing.T) {
+			got, err := Next(schedules[tt.schedule], mustParse(t, tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParse(t, tt.want); !got.Equal(want) {
+				t.Errorf("Next = %s, want %s", got.Format(time.RFC3339), want.Format(time.RFC3339))
+			}
+		})
+	}
+}
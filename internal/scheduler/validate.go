@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+)
+
+var (
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+// Validate checks that the schedule is complete and that its time, cron
+// expression or interval can be parsed. It does not check that its desk and
+// presets exist.
+func Validate(schedule config.Schedule) error {
+	if schedule.Name == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidSchedule)
+	}
+	if schedule.DeskName == "" {
+		return fmt.Errorf("%w: missing desk", ErrInvalidSchedule)
+	}
+
+	for _, day := range schedule.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: invalid day %d", ErrInvalidSchedule, day)
+		}
+	}
+	err := config.ValidatePlanName(schedule.Plan)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	switch {
+	case schedule.Kind == config.KindInterval:
+		err := ValidateInterval(schedule.Interval)
+		if err != nil {
+			return err
+		}
+		if len(schedule.Days) == 0 {
+			return fmt.Errorf("%w: an interval schedule needs days", ErrInvalidSchedule)
+		}
+	case schedule.Kind == config.KindOnce:
+		if schedule.At.IsZero() {
+			return fmt.Errorf("%w: a one-off schedule needs a time", ErrInvalidSchedule)
+		}
+	case schedule.Kind != config.KindFixed:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidSchedule, schedule.Kind)
+	case schedule.Cron != "":
+		_, err := ParseCron(schedule.Cron)
+		if err != nil {
+			return err
+		}
+	default:
+		_, err := time.Parse("15:04", schedule.Time)
+		if err != nil {
+			return fmt.Errorf("%w: invalid time %q, expected HH:MM", ErrInvalidSchedule, schedule.Time)
+		}
+		if len(schedule.Days) == 0 {
+			return fmt.Errorf("%w: a schedule with a time needs days", ErrInvalidSchedule)
+		}
+	}
+
+	if schedule.Action != nil {
+		err := schedule.Action.Validate()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+		}
+	}
+	if schedule.Kind == config.KindInterval && schedule.ActionKind() != config.ActionPreset {
+		return fmt.Errorf("%w: interval schedules can only move to their presets", ErrInvalidSchedule)
+	}
+	if schedule.Kind != config.KindInterval && schedule.ActionKind() == config.ActionPreset && schedule.PresetName == "" {
+		return fmt.Errorf("%w: missing preset", ErrInvalidSchedule)
+	}
+
+	if schedule.Jitter < 0 || schedule.Jitter > MaxJitter {
+		return fmt.Errorf("%w: the jitter must be between 0 and %s", ErrInvalidSchedule, MaxJitter)
+	}
+	if schedule.Jitter > 0 && schedule.Kind != config.KindFixed {
+		return fmt.Errorf("%w: only schedules with a time or cron expression can have a jitter", ErrInvalidSchedule)
+	}
+
+	if _, err := schedule.Location(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	if schedule.GracePeriod < 0 || schedule.RetryBackoff < 0 || schedule.Countdown < 0 || schedule.Retries < 0 {
+		return fmt.Errorf("%w: durations and retries must not be negative", ErrInvalidSchedule)
+	}
+
+	_, err = config.ParseMissedPolicy(string(schedule.MissedPolicy))
+	return err
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// State is what the daemon remembers across restarts.
+type State struct {
+	// LastCheck is when the scheduler last looked for due runs, so runs
+	// missed while the daemon was not running can be found on start.
+	LastCheck time.Time                `yaml:"lastCheck,omitempty"`
+	Intervals map[string]IntervalPhase `yaml:"intervals,omitempty"` // Keyed by schedule name
+
+	// Paused stops scheduled moves until PausedUntil, or until resumed when
+	// PausedUntil is zero.
+	Paused      bool      `yaml:"paused,omitempty"`
+	PausedUntil time.Time `yaml:"pausedUntil,omitempty"`
+}
+
+// PausedAt reports whether scheduled moves are paused at t.
+func (s State) PausedAt(t time.Time) bool {
+	return s.Paused && (s.PausedUntil.IsZero() || t.Before(s.PausedUntil))
+}
+
+// IntervalPhase is the last phase an interval schedule moved the desk to.
+type IntervalPhase struct {
+	PresetName string    `yaml:"presetName"`
+	Since      time.Time `yaml:"since"`
+}
+
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	state State
+}
+
+// StateFile returns the path of the state file kept next to configFile.
+func StateFile(configFile string) string {
+	return configFile + ".state.yaml"
+}
+
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Join(err, errors.New("could not read state file"))
+	}
+
+	err = yaml.Unmarshal(b, &s.state)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not parse state file"))
+	}
+
+	return s, nil
+}
+
+// Get returns a copy of the current state.
+func (s *Store) Get() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state
+	state.Intervals = make(map[string]IntervalPhase, len(s.state.Intervals))
+	for name, phase := range s.state.Intervals {
+		state.Intervals[name] = phase
+	}
+	return state
+}
+
+// Update applies fn to the state and stores the result.
+func (s *Store) Update(fn func(state *State)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state.Intervals == nil {
+		s.state.Intervals = make(map[string]IntervalPhase)
+	}
+	fn(&s.state)
+
+	b, err := yaml.Marshal(s.state)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save state file"))
+	}
+
+	err = atomicfile.Write(s.path, b, 0644)
+	if err != nil {
+		return errors.Join(err, errors.New("could not save state file"))
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samueltorres/idasenctl/internal/config"
+	"github.com/samueltorres/idasenctl/internal/history"
+)
+
+// DefaultMaxGap is how long a desk is assumed to stay at an observed height
+// when no later sample or move is known.
+const DefaultMaxGap = 30 * time.Minute
+
+// Point is a desk height known at a point in time.
+type Point struct {
+	Time   time.Time
+	Height float32
+}
+
+// Totals is the time spent sitting and standing over a period.
+type Totals struct {
+	Sitting  time.Duration
+	Standing time.Duration
+}
+
+func (t Totals) Total() time.Duration {
+	return t.Sitting + t.Standing
+}
+
+// StandingRatio returns the share of the tracked time spent standing.
+func (t Totals) StandingRatio() float64 {
+	if t.Total() == 0 {
+		return 0
+	}
+	return float64(t.Standing) / float64(t.Total())
+}
+
+func (t *Totals) add(o Totals) {
+	t.Sitting += o.Sitting
+	t.Standing += o.Standing
+}
+
+type Day struct {
+	Date time.Time
+	Totals
+}
+
+func (d Day) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Date string `json:"date"`
+		totalsJSON
+	}{d.Date.Format(time.DateOnly), newTotalsJSON(d.Totals)})
+}
+
+// Week starts on Monday.
+type Week struct {
+	Start time.Time
+	Totals
+}
+
+func (w Week) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Start string `json:"start"`
+		totalsJSON
+	}{w.Start.Format(time.DateOnly), newTotalsJSON(w.Totals)})
+}
+
+type totalsJSON struct {
+	SittingMinutes  float64 `json:"sittingMinutes"`
+	StandingMinutes float64 `json:"standingMinutes"`
+	StandingRatio   float64 `json:"standingRatio"`
+}
+
+func newTotalsJSON(t Totals) totalsJSON {
+	return totalsJSON{
+		SittingMinutes:  t.Sitting.Minutes(),
+		StandingMinutes: t.Standing.Minutes(),
+		StandingRatio:   t.StandingRatio(),
+	}
+}
+
+type Report struct {
+	Desk           string  `json:"desk"`
+	StandingHeight float32 `json:"standingHeight"`
+	Days           []Day   `json:"days"`
+	Weeks          []Week  `json:"weeks"`
+}
+
+// Points merges height samples and the heights before and after recorded
+// moves, oldest first.
+func Points(samples []history.Sample, records []history.Record) []Point {
+	var points []Point
+	for _, sample := range samples {
+		points = append(points, Point{Time: sample.Time, Height: sample.Height})
+	}
+	for _, r := range records {
+		if r.Outcome != history.OutcomeSuccess && r.Outcome != history.OutcomeCancelled {
+			continue
+		}
+		if r.FromHeight > 0 {
+			points = append(points, Point{Time: r.Time, Height: r.FromHeight})
+		}
+		if r.ToHeight > 0 {
+			points = append(points, Point{Time: r.Time.Add(r.Duration()), Height: r.ToHeight})
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+	return points
+}
+
+// Compute splits the time between from and to into sitting and standing per
+// day and week. The desk is assumed to stay at each point's height until the
+// next point, but for at most maxGap; time without a known height is not
+// counted.
+func Compute(desk string, standingHeight float32, points []Point, from time.Time, to time.Time, maxGap time.Duration) Report {
+	report := Report{
+		Desk:           desk,
+		StandingHeight: standingHeight,
+	}
+
+	dayIndex := make(map[string]int)
+	for date := startOfDay(from); date.Before(to); date = date.AddDate(0, 0, 1) {
+		dayIndex[date.Format(time.DateOnly)] = len(report.Days)
+		report.Days = append(report.Days, Day{Date: date})
+	}
+
+	for i, point := range points {
+		end := point.Time.Add(maxGap)
+		if i+1 < len(points) && points[i+1].Time.Before(end) {
+			end = points[i+1].Time
+		}
+		start := maxTime(point.Time, from).In(from.Location())
+		end = minTime(end, to)
+
+		standing := point.Height >= standingHeight
+		for start.Before(end) {
+			day := startOfDay(start)
+			segmentEnd := minTime(end, day.AddDate(0, 0, 1))
+
+			if index, ok := dayIndex[day.Format(time.DateOnly)]; ok {
+				if standing {
+					report.Days[index].Standing += segmentEnd.Sub(start)
+				} else {
+					report.Days[index].Sitting += segmentEnd.Sub(start)
+				}
+			}
+			start = segmentEnd
+		}
+	}
+
+	for _, day := range report.Days {
+		weekStart := startOfWeek(day.Date)
+		if len(report.Weeks) == 0 || !report.Weeks[len(report.Weeks)-1].Start.Equal(weekStart) {
+			report.Weeks = append(report.Weeks, Week{Start: weekStart})
+		}
+		report.Weeks[len(report.Weeks)-1].add(day.Totals)
+	}
+
+	return report
+}
+
+// Load computes the report of a desk from the samples and moves recorded
+// between from and to.
+func Load(runs *history.Store, samples *history.SampleStore, desk config.Desk, from time.Time, to time.Time, maxGap time.Duration) (Report, error) {
+	// Include points before from whose height still lasts into the period.
+	since := from.Add(-maxGap)
+
+	heights, err := samples.Query(desk.Name, since, to)
+	if err != nil {
+		return Report{}, err
+	}
+
+	records, err := runs.Query(history.Filter{Desk: desk.Name, Since: since, Until: to})
+	if err != nil {
+		return Report{}, err
+	}
+
+	return Compute(desk.Name, desk.StandingThreshold(), Points(heights, records), from, to, maxGap), nil
+}
+
+// Total returns the totals over the whole report.
+func (r Report) Total() Totals {
+	var total Totals
+	for _, day := range r.Days {
+		total.add(day.Totals)
+	}
+	return total
+}
+
+// FormatDuration formats a duration as hours and minutes, like 1h05m.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,108 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	// Standing from 1.0 m. 2026-10-19 is a Monday.
+	points := []Point{
+		{Time: at(18, 23, 50), Height: 0.7},
+		{Time: at(19, 9, 0), Height: 1.1},
+		{Time: at(19, 9, 10), Height: 0.7},
+		// Nothing is known from 09:40, maxGap after the last point.
+		{Time: at(19, 12, 0), Height: 1.0},
+		{Time: at(19, 12, 5), Height: 0.7},
+		{Time: at(19, 23, 50), Height: 1.1},
+		{Time: at(20, 0, 10), Height: 0.7},
+		{Time: at(20, 23, 45), Height: 1.1},
+	}
+	type totals struct{ sitting, standing time.Duration }
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		days     []totals
+		weeks    []totals
+	}{
+		{
+			name: "split at midnight and range bounds",
+			from: at(19, 0, 0),
+			to:   at(21, 0, 0),
+			days: []totals{
+				{sitting: 20*time.Minute + 30*time.Minute + 30*time.Minute, standing: 5*time.Minute + 10*time.Minute + 10*time.Minute},
+				{sitting: 30 * time.Minute, standing: 10*time.Minute + 15*time.Minute},
+			},
+			weeks: []totals{{sitting: 110 * time.Minute, standing: 50 * time.Minute}},
+		},
+		{
+			name: "weeks start on Monday",
+			from: at(18, 0, 0),
+			to:   at(20, 0, 0),
+			days: []totals{
+				{sitting: 10 * time.Minute},
+				{sitting: 80 * time.Minute, standing: 25 * time.Minute},
+			},
+			weeks: []totals{{sitting: 10 * time.Minute}, {sitting: 80 * time.Minute, standing: 25 * time.Minute}},
+		},
+		{
+			name:  "within a day",
+			from:  at(19, 9, 5),
+			to:    at(19, 9, 35),
+			days:  []totals{{sitting: 25 * time.Minute, standing: 5 * time.Minute}},
+			weeks: []totals{{sitting: 25 * time.Minute, standing: 5 * time.Minute}},
+		},
+		{
+			name:  "no points",
+			from:  at(22, 0, 0),
+			to:    at(23, 0, 0),
+			days:  []totals{{}},
+			weeks: []totals{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compute("desk", 1.0, points, tt.from, tt.to, 30*time.Minute)
+
+			if len(report.Days) != len(tt.days) {
+				t.Fatalf("got %d days, want %d", len(report.Days), len(tt.days))
+			}
+			for i, day := range report.Days {
+				if got := (totals{day.Sitting, day.Standing}); got != tt.days[i] {
+					t.Errorf("day %s sitting %s standing %s, want %s and %s", day.Date.Format(time.DateOnly), got.sitting, got.standing, tt.days[i].sitting, tt.days[i].standing)
+				}
+			}
+
+			if len(report.Weeks) != len(tt.weeks) {
+				t.Fatalf("got %d weeks, want %d", len(report.Weeks), len(tt.weeks))
+			}
+			for i, week := range report.Weeks {
+				if week.Start.Weekday() != time.Monday {
+					t.Errorf("week starts on %s, want Monday", week.Start.Weekday())
+				}
+				if got := (totals{week.Sitting, week.Standing}); got != tt.weeks[i] {
+					t.Errorf("week of %s sitting %s standing %s, want %s and %s", week.Start.Format(time.DateOnly), got.sitting, got.standing, tt.weeks[i].sitting, tt.weeks[i].standing)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                               "0m",
+		59*time.Minute + 29*time.Second: "59m",
+		59*time.Minute + 30*time.Second: "1h00m",
+		time.Hour + 5*time.Minute:       "1h05m",
+		26*time.Hour + 40*time.Minute:   "26h40m",
+	} {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%s) = %s, want %s", d, got, want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package desklist
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -15,7 +17,7 @@ var docStyle = lipgloss.NewStyle().Margin(1, 2)
 type deskItem struct {
 	name        string
 	address     string
-	presetCount int
+	presetNames []string
 	isDefault   bool
 }
 
@@ -28,7 +30,10 @@ func (i deskItem) Title() string {
 }
 
 func (i deskItem) Description() string {
-	return fmt.Sprintf("Address: %s | Presets: %d", i.address, i.presetCount)
+	if len(i.presetNames) == 0 {
+		return fmt.Sprintf("Address: %s | Presets: none", i.address)
+	}
+	return fmt.Sprintf("Address: %s | Presets: %s", i.address, strings.Join(i.presetNames, ", "))
 }
 
 func (i deskItem) FilterValue() string {
@@ -72,22 +77,33 @@ func NewProgram(configManager *config.ConfigManager) *DeskListProgram {
 	desks := configManager.GetAllDesks()
 	defaultDesk := configManager.GetDefaultDesk()
 
+	names := make([]string, 0, len(desks))
+	for name := range desks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	var items []list.Item
-	for _, desk := range desks {
+	for _, name := range names {
+		desk := desks[name]
+		var presetNames []string
+		for _, preset := range desk.SortedPresets(config.SortByOrder) {
+			presetNames = append(presetNames, preset.Name)
+		}
+
 		items = append(items, deskItem{
 			name:        desk.Name,
 			address:     desk.Address,
-			presetCount: len(desk.Presets),
+			presetNames: presetNames,
 			isDefault:   desk.Name == defaultDesk,
 		})
 	}
 
 	if len(items) == 0 {
 		items = append(items, deskItem{
-			name:        "No desks configured",
-			address:     "Run 'idasenctl desk add' to add a desk",
-			presetCount: 0,
-			isDefault:   false,
+			name:      "No desks configured",
+			address:   "Run 'idasenctl desk add' to add a desk",
+			isDefault: false,
 		})
 	}
 
@@ -122,3 +122,8 @@ func (p *DeskMoveProgram) Run(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Quit stops the program, for example when the move failed.
+func (p *DeskMoveProgram) Quit() {
+	p.teaProgram.Quit()
+}
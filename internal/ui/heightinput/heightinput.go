@@ -0,0 +1,107 @@
+package heightinput
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	docStyle   = lipgloss.NewStyle().Margin(1, 2)
+	helpStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).Render
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")).Render
+)
+
+type heightInputModel struct {
+	title    string
+	input    textinput.Model
+	validate func(float32) error
+	height   *float32
+	err      error
+}
+
+func (m *heightInputModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *heightInputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			height, err := parseHeight(m.input.Value())
+			if err == nil {
+				err = m.validate(height)
+			}
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.height = &height
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *heightInputModel) View() string {
+	view := m.title + "\n\n" + m.input.View() + "\n\n"
+	if m.err != nil {
+		view += errorStyle(m.err.Error()) + "\n\n"
+	}
+	view += helpStyle("enter: confirm • esc: cancel")
+	return docStyle.Render(view)
+}
+
+func parseHeight(s string) (float32, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "m")
+	height, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %q, use meters like 1.05", s)
+	}
+	return float32(height), nil
+}
+
+type HeightInputProgram struct {
+	teaProgram *tea.Program
+	model      *heightInputModel
+}
+
+// NewProgram creates a prompt asking for a height in meters that is only
+// accepted once validate returns no error.
+func NewProgram(title string, placeholder string, validate func(float32) error) *HeightInputProgram {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.Prompt = "Height (m): "
+	input.CharLimit = 8
+	input.Focus()
+
+	m := &heightInputModel{
+		title:    title,
+		input:    input,
+		validate: validate,
+	}
+
+	return &HeightInputProgram{
+		teaProgram: tea.NewProgram(m),
+		model:      m,
+	}
+}
+
+func (p *HeightInputProgram) Run() error {
+	_, err := p.teaProgram.Run()
+	return err
+}
+
+// GetHeight returns the entered height, or nil if the prompt was cancelled.
+func (p *HeightInputProgram) GetHeight() *float32 {
+	return p.model.height
+}
@@ -13,8 +13,9 @@ import (
 var docStyle = lipgloss.NewStyle().Margin(1, 2)
 
 type presetItem struct {
-	name   string
-	height float32
+	name        string
+	height      float32
+	description string
 }
 
 func (i presetItem) Title() string {
@@ -22,6 +23,9 @@ func (i presetItem) Title() string {
 }
 
 func (i presetItem) Description() string {
+	if i.description != "" {
+		return fmt.Sprintf("Height: %.2f m | %s", i.height, i.description)
+	}
 	return fmt.Sprintf("Height: %.2f m", i.height)
 }
 
@@ -62,7 +66,7 @@ type PresetListProgram struct {
 	teaProgram *tea.Program
 }
 
-func NewProgram(configManager *config.ConfigManager, deskName string) *PresetListProgram {
+func NewProgram(configManager *config.ConfigManager, deskName string, sortBy config.PresetSort) *PresetListProgram {
 	desk, err := configManager.GetDesk(deskName)
 
 	var items []list.Item
@@ -77,10 +81,11 @@ func NewProgram(configManager *config.ConfigManager, deskName string) *PresetLis
 	} else {
 		title = fmt.Sprintf("Presets for %s", deskName)
 
-		for _, preset := range desk.Presets {
+		for _, preset := range desk.SortedPresets(sortBy) {
 			items = append(items, presetItem{
-				name:   preset.Name,
-				height: preset.Height,
+				name:        preset.Name,
+				height:      preset.Height,
+				description: preset.Description,
 			})
 		}
 
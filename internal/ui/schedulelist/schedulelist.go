@@ -3,6 +3,7 @@ package schedulelist
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -20,6 +21,13 @@ type scheduleItem struct {
 	presetName string
 	enabled    bool
 	days       []int
+	cron       string
+	interval   *config.IntervalSchedule
+	timezone   string
+	at         time.Time
+	plan       string
+	jitter     time.Duration
+	action     *config.Action
 }
 
 func (i scheduleItem) Title() string {
@@ -31,26 +39,60 @@ func (i scheduleItem) Title() string {
 }
 
 func (i scheduleItem) Description() string {
-	daysStr := formatDays(i.days)
-	return fmt.Sprintf("Time: %s | Desk: %s | Preset: %s | Days: %s",
-		i.time, i.deskName, i.presetName, daysStr)
+	var description string
+	switch {
+	case !i.at.IsZero():
+		description = fmt.Sprintf("Once: %s | Desk: %s | %s", i.at.Format("Mon 2006-01-02 15:04"), i.deskName, i.describeAction())
+	case i.interval != nil:
+		description = fmt.Sprintf("Interval: %s %s / %s %s | Window: %s-%s | Desk: %s | Days: %s",
+			i.interval.FirstPreset, formatDuration(i.interval.FirstDuration),
+			i.interval.SecondPreset, formatDuration(i.interval.SecondDuration),
+			i.interval.Start, i.interval.End, i.deskName, config.FormatDays(i.days))
+	case i.cron != "":
+		description = fmt.Sprintf("Cron: %s | Desk: %s | %s", i.cron, i.deskName, i.describeAction())
+	default:
+		description = fmt.Sprintf("Time: %s | Desk: %s | %s | Days: %s",
+			i.time, i.deskName, i.describeAction(), config.FormatDays(i.days))
+	}
+	if i.jitter > 0 {
+		description += " | Jitter: ±" + formatDuration(i.jitter)
+	}
+	if i.timezone != "" {
+		description += " | Time zone: " + i.timezone
+	}
+	if i.plan != "" {
+		description += " | Plan: " + i.plan
+	}
+	return description
+}
+
+// describeAction returns what the schedule does, such as "Preset: stand".
+func (i scheduleItem) describeAction() string {
+	if i.action == nil {
+		return "Preset: " + i.presetName
+	}
+	switch i.action.Kind {
+	case config.ActionHeight:
+		return fmt.Sprintf("Height: %.2f m", i.action.Height)
+	case config.ActionToggle:
+		return fmt.Sprintf("Toggle: %s / %s", i.action.SitPreset, i.action.StandPreset)
+	case config.ActionNotify:
+		return fmt.Sprintf("Notify: %q", i.action.NotifyMessage())
+	case config.ActionCommand:
+		return "Command: " + i.action.Command
+	}
+	return "Preset: " + i.presetName
 }
 
 func (i scheduleItem) FilterValue() string {
 	return i.name
 }
 
-func formatDays(days []int) string {
-	dayNames := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-	var dayStrings []string
-
-	for _, day := range days {
-		if day >= 0 && day <= 6 {
-			dayStrings = append(dayStrings, dayNames[day])
-		}
-	}
-
-	return strings.Join(dayStrings, ",")
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	s = strings.TrimSuffix(s, "0s")
+	s = strings.TrimSuffix(s, "0m")
+	return s
 }
 
 type scheduleListModel struct {
@@ -98,6 +140,13 @@ func NewProgram(configManager *config.ConfigManager) *ScheduleListProgram {
 			presetName: schedule.PresetName,
 			enabled:    schedule.Enabled,
 			days:       schedule.Days,
+			cron:       schedule.Cron,
+			interval:   schedule.Interval,
+			timezone:   schedule.Timezone,
+			at:         schedule.At,
+			plan:       schedule.Plan,
+			jitter:     schedule.Jitter,
+			action:     schedule.Action,
 		})
 	}
 
@@ -0,0 +1,136 @@
+package statsview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/samueltorres/idasenctl/internal/stats"
+)
+
+const (
+	padding     = 2
+	maxBarWidth = 50
+)
+
+var (
+	docStyle      = lipgloss.NewStyle().Margin(1, 2)
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	standingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	sittingStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5A56E0"))
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+)
+
+type row struct {
+	label string
+	stats.Totals
+}
+
+type statsModel struct {
+	report   stats.Report
+	showWeek bool
+	width    int
+}
+
+func (m statsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m statsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "tab", "w", "d":
+			m.showWeek = !m.showWeek
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+func (m statsModel) rows() []row {
+	var rows []row
+	if m.showWeek {
+		for _, week := range m.report.Weeks {
+			rows = append(rows, row{label: "Week of " + week.Start.Format("Jan 02"), Totals: week.Totals})
+		}
+		return rows
+	}
+	for _, day := range m.report.Days {
+		rows = append(rows, row{label: day.Date.Format("Mon Jan 02"), Totals: day.Totals})
+	}
+	return rows
+}
+
+func (m statsModel) View() string {
+	rows := m.rows()
+
+	barWidth := maxBarWidth
+	if m.width > 0 {
+		barWidth = min(maxBarWidth, max(10, m.width-padding*2-50))
+	}
+
+	var longest time.Duration
+	labelWidth := 0
+	for _, r := range rows {
+		longest = max(longest, r.Total())
+		labelWidth = max(labelWidth, len(r.label))
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Sit/stand statistics for %s", m.report.Desk)))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  standing from %.2f m", m.report.StandingHeight)))
+	b.WriteString("\n\n")
+
+	for _, r := range rows {
+		standing, sitting := 0, 0
+		if longest > 0 {
+			standing = int(float64(barWidth) * float64(r.Standing) / float64(longest))
+			sitting = int(float64(barWidth) * float64(r.Sitting) / float64(longest))
+		}
+
+		b.WriteString(fmt.Sprintf("%-*s ", labelWidth, r.label))
+		b.WriteString(standingStyle.Render(strings.Repeat("█", standing)))
+		b.WriteString(sittingStyle.Render(strings.Repeat("█", sitting)))
+		b.WriteString(strings.Repeat(" ", barWidth-standing-sitting))
+		if r.Total() == 0 {
+			b.WriteString(helpStyle.Render("  no data"))
+		} else {
+			b.WriteString(fmt.Sprintf("  %s standing, %s sitting (%.0f%%)",
+				stats.FormatDuration(r.Standing), stats.FormatDuration(r.Sitting), r.StandingRatio()*100))
+		}
+		b.WriteString("\n")
+	}
+
+	total := m.report.Total()
+	b.WriteString(fmt.Sprintf("\nTotal: %s standing, %s sitting (%.0f%%)\n\n",
+		stats.FormatDuration(total.Standing), stats.FormatDuration(total.Sitting), total.StandingRatio()*100))
+	b.WriteString(standingStyle.Render("█") + " standing  " + sittingStyle.Render("█") + " sitting\n")
+	b.WriteString(helpStyle.Render("tab: days/weeks • q: quit"))
+
+	return docStyle.Render(b.String())
+}
+
+type StatsProgram struct {
+	teaProgram *tea.Program
+}
+
+func NewProgram(report stats.Report) *StatsProgram {
+	m := statsModel{
+		report: report,
+	}
+
+	return &StatsProgram{
+		teaProgram: tea.NewProgram(m),
+	}
+}
+
+func (p *StatsProgram) Run() error {
+	_, err := p.teaProgram.Run()
+	return err
+}